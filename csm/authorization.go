@@ -0,0 +1,76 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// authorizationSidecar is the sidecar injected into the driver pods by CSM Authorization
+const authorizationSidecar = "karavi-authorization-proxy"
+
+// authorizationServices are the deployments of the CSM Authorization proxy server
+var authorizationServices = []string{"proxy-server", "tenant-service", "role-service", "storage-service", "redis"}
+
+func init() {
+	RegisterModule(Module{
+		Name:        "authorization",
+		DisplayName: "Authorization",
+		Detect: func() []string {
+			return detectDeployments("proxy-server", "tenant-service", "role-service", "storage-service")
+		},
+		Collect: collectAuthorization,
+	})
+}
+
+// collectAuthorization collects the logs of the proxy server services, their config and the
+// driver pods carrying the authorization sidecar
+func collectAuthorization(namespace string, moduleDirectoryName string, dateRange *metav1.Time) {
+	collectModulePods(namespace, moduleDirectoryName, dateRange, authorizationServices...)
+	collectModuleConfig(namespace, moduleDirectoryName, "karavi-config", "karavi-storage", "karavi-roles", "csm-config-params")
+
+	sidecars, err := authorizationSidecars()
+	if err != nil {
+		reportErrors("Getting driver pods with the authorization sidecar", err)
+		return
+	}
+	captureLOG(moduleDirectoryName, "sidecars.txt", sidecars)
+}
+
+// authorizationSidecars records for every driver pod if it carries the authorization sidecar
+func authorizationSidecars() (string, error) {
+	podList, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("getting all pods failed with error: %s", err.Error())
+	}
+	var sidecars strings.Builder
+	fmt.Fprintf(&sidecars, "Driver pods with the %s sidecar:\n", authorizationSidecar)
+	for _, pod := range podList.Items {
+		isDriverPod := false
+		for _, container := range pod.Spec.Containers {
+			if _, ok := driverForImage(container.Image); ok {
+				isDriverPod = true
+				break
+			}
+		}
+		if isDriverPod {
+			fmt.Fprintf(&sidecars, "\t%s/%s: %t\n", pod.Namespace, pod.Name, hasContainer(&pod, authorizationSidecar))
+		}
+	}
+	return sidecars.String(), nil
+}
@@ -0,0 +1,94 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	utils "csm-logcollector/utils"
+	"fmt"
+	"os"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ClusterAccess selects the kubeconfig used to access the cluster
+type ClusterAccess struct {
+	// Kubeconfig is the kubeconfig file, it takes precedence over KUBECONFIG and kubeconfig_details of config.yml
+	Kubeconfig string
+	// Context is the kubeconfig context, the current context is used when empty
+	Context string
+	// Remote copies the kubeconfig of kubeconfig_details from the cluster over SSH
+	Remote bool
+}
+
+var clusterAccess ClusterAccess
+
+// SetClusterAccess selects the kubeconfig used to access the cluster
+func SetClusterAccess(access ClusterAccess) {
+	clusterAccess = access
+	utils.SetRemoteCluster(access.Remote)
+}
+
+// loadingRules selects the kubeconfig. It is, by precedence, the one copied over SSH when requested, the
+// --kubeconfig file, the files of KUBECONFIG merged, the path of kubeconfig_details when the file exists and
+// ~/.kube/config. The default rules fall back to the in-cluster configuration when no kubeconfig is found.
+func loadingRules() (*clientcmd.ClientConfigLoadingRules, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	switch {
+	case clusterAccess.Remote:
+		if clusterIPAddress == "" || kubeconfigPath == "" {
+			return nil, fmt.Errorf("path and ip_address of kubeconfig_details in config.yml are required to copy the kubeconfig")
+		}
+		rules.ExplicitPath = utils.ScpConfigFile(kubeconfigPath, clusterIPAddress, utils.GetRemoteSSHConfig(), ".")
+	case clusterAccess.Kubeconfig != "":
+		rules.ExplicitPath = clusterAccess.Kubeconfig
+	case os.Getenv(clientcmd.RecommendedConfigPathEnvVar) == "" && kubeconfigPath != "":
+		// the default path of config.yml does not exist in a pod, where the in-cluster configuration is used
+		if _, err := os.Stat(kubeconfigPath); err == nil {
+			rules.ExplicitPath = kubeconfigPath
+		} else {
+			snsLog.Infof("kubeconfig %s of kubeconfig_details not found, using the default loading rules", kubeconfigPath)
+		}
+	}
+	return rules, nil
+}
+
+// buildRestConfig loads the client configuration from the kubeconfig selected by loadingRules
+func buildRestConfig() (*rest.Config, error) {
+	rules, err := loadingRules()
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: clusterAccess.Context}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	rawConfig, err := clientConfig.RawConfig()
+	if err == nil && len(rawConfig.Contexts) > 0 {
+		context := rawConfig.CurrentContext
+		if clusterAccess.Context != "" {
+			context = clusterAccess.Context
+		}
+		fmt.Printf("Using kubeconfig context %s, API server: %s\n", context, config.Host)
+		snsLog.Infof("Using kubeconfig context %s, API server: %s", context, config.Host)
+	} else {
+		fmt.Printf("Using in-cluster configuration, API server: %s\n", config.Host)
+		snsLog.Infof("Using in-cluster configuration, API server: %s", config.Host)
+	}
+	return config, nil
+}
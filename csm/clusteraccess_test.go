@@ -0,0 +1,115 @@
+package csm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// CreateKubeconfig writes a kubeconfig with a context per cluster, the first context being the current one
+func CreateKubeconfig(t *testing.T, directoryName string, fileName string, clusters ...string) string {
+	content := "apiVersion: v1\nkind: Config\ncurrent-context: " + clusters[0] + "\nclusters:\n"
+	for _, cluster := range clusters {
+		content += fmt.Sprintf("- name: %s\n  cluster:\n    server: https://%s:6443\n", cluster, cluster)
+	}
+	content += "contexts:\n"
+	for _, cluster := range clusters {
+		content += fmt.Sprintf("- name: %s\n  context:\n    cluster: %s\n    user: %s\n", cluster, cluster, cluster)
+	}
+	content += "users:\n"
+	for _, cluster := range clusters {
+		content += fmt.Sprintf("- name: %s\n  user:\n    token: %s-token\n", cluster, cluster)
+	}
+	path := filepath.Join(directoryName, fileName)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("kubeconfig not written: %s", err)
+	}
+	return path
+}
+
+func TestBuildRestConfig(t *testing.T) {
+	directoryName, _ := ioutil.TempDir("", "kubeconfig")
+	defer os.RemoveAll(directoryName)
+	first := CreateKubeconfig(t, directoryName, "first", "cluster-a", "cluster-b")
+	second := CreateKubeconfig(t, directoryName, "second", "cluster-c")
+
+	type tests = []struct {
+		description   string
+		access        ClusterAccess
+		kubeconfigEnv string
+		configPath    string
+		expectedHost  string
+		expectedErr   bool
+	}
+	var buildRestConfigTests = tests{
+		{"current context of kubeconfig flag", ClusterAccess{Kubeconfig: first}, second, "", "https://cluster-a:6443", false},
+		{"context of kubeconfig flag", ClusterAccess{Kubeconfig: first, Context: "cluster-b"}, "", "", "https://cluster-b:6443", false},
+		{"context of merged KUBECONFIG files", ClusterAccess{Context: "cluster-c"}, first + string(os.PathListSeparator) + second, "", "https://cluster-c:6443", false},
+		{"KUBECONFIG preferred to kubeconfig_details", ClusterAccess{}, second, first, "https://cluster-c:6443", false},
+		{"path of kubeconfig_details", ClusterAccess{}, "", first, "https://cluster-a:6443", false},
+		{"missing path of kubeconfig_details falls back to KUBECONFIG", ClusterAccess{}, second, filepath.Join(directoryName, "missing"), "https://cluster-c:6443", false},
+		{"unknown context", ClusterAccess{Kubeconfig: first, Context: "cluster-x"}, "", "", "", true},
+		{"remote kubeconfig without kubeconfig_details", ClusterAccess{Remote: true}, "", "", "", true},
+	}
+	defer func() { kubeconfigPath = "" }()
+	defer SetClusterAccess(ClusterAccess{})
+	for _, test := range buildRestConfigTests {
+		t.Run(test.description, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", test.kubeconfigEnv)
+			kubeconfigPath = test.configPath
+			SetClusterAccess(test.access)
+
+			config, err := buildRestConfig()
+			if diff := cmp.Diff(err != nil, test.expectedErr); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedErr, diff)
+			}
+			if err == nil {
+				if diff := cmp.Diff(config.Host, test.expectedHost); diff != "" {
+					t.Errorf("%T differ (-got, +want): %s", test.expectedHost, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadingRules(t *testing.T) {
+	directoryName, _ := ioutil.TempDir("", "kubeconfig")
+	defer os.RemoveAll(directoryName)
+	first := CreateKubeconfig(t, directoryName, "first", "cluster-a")
+	missing := filepath.Join(directoryName, "missing")
+
+	type tests = []struct {
+		description          string
+		access               ClusterAccess
+		kubeconfigEnv        string
+		configPath           string
+		expectedExplicitPath string
+	}
+	var loadingRulesTests = tests{
+		{"existing path of kubeconfig_details", ClusterAccess{}, "", first, first},
+		{"missing path of kubeconfig_details without KUBECONFIG", ClusterAccess{}, "", missing, ""},
+		{"missing kubeconfig flag", ClusterAccess{Kubeconfig: missing}, "", first, missing},
+		{"KUBECONFIG preferred to kubeconfig_details", ClusterAccess{}, first, first, ""},
+	}
+	defer func() { kubeconfigPath = "" }()
+	defer SetClusterAccess(ClusterAccess{})
+	for _, test := range loadingRulesTests {
+		t.Run(test.description, func(t *testing.T) {
+			t.Setenv("KUBECONFIG", test.kubeconfigEnv)
+			kubeconfigPath = test.configPath
+			SetClusterAccess(test.access)
+
+			rules, err := loadingRules()
+			if err != nil {
+				t.Fatalf("loading rules failed: %s", err)
+			}
+			if diff := cmp.Diff(rules.ExplicitPath, test.expectedExplicitPath); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedExplicitPath, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// configParamsSuffix is the name suffix of the ConfigMap holding the log level and format of the driver
+const configParamsSuffix = "-config-params"
+
+// verboseLogLevels are the log levels at which the driver logs are sufficient for the analysis
+var verboseLogLevels = []string{"debug", "trace"}
+
+// collectConfigParams records the content of the config params ConfigMaps of the driver namespace
+// and the log level of the driver, a warning is added to the summary when the level is above debug
+func (s StorageNameSpaceStruct) collectConfigParams(namespaceDirectoryName string) {
+	configMaps, err := clientset.CoreV1().ConfigMaps(s.namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		reportErrors("Getting configmaps in namespace "+s.namespaceName, err)
+		return
+	}
+	var content strings.Builder
+	logLevel := ""
+	for _, configMap := range configMaps.Items {
+		if !strings.HasSuffix(configMap.Name, configParamsSuffix) {
+			continue
+		}
+		fmt.Fprintf(&content, "ConfigMap: %s\n", configMap.Name)
+		var keys []string
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&content, "%s:\n%s\n", key, configMap.Data[key])
+		}
+		if level := configParamsLogLevel(configMap); level != "" {
+			logLevel = level
+		}
+	}
+	if content.Len() == 0 {
+		fmt.Printf("\tConfigMap *%s not found in namespace %s\n", configParamsSuffix, s.namespaceName)
+		addSummary(s.namespaceName, "WARNING: log level unknown, ConfigMap *%s not found", configParamsSuffix)
+		return
+	}
+	if err := writeSanitized(namespaceDirectoryName, "driver-config-params.txt", []byte(content.String()), getSensitiveContent(s.namespaceName)); err != nil {
+		reportErrors("Writing driver config params", err)
+	}
+
+	switch {
+	case logLevel == "":
+		addSummary(s.namespaceName, "WARNING: log level unknown, CSI_LOG_LEVEL not set in ConfigMap *%s", configParamsSuffix)
+	case containsAny(strings.ToLower(logLevel), verboseLogLevels):
+		fmt.Printf("\tLog level: \t%s\n", logLevel)
+		addSummary(s.namespaceName, "Log level: %s", logLevel)
+	default:
+		fmt.Printf("\tLog level: \t%s, the driver logs may be insufficient, set CSI_LOG_LEVEL to debug\n", logLevel)
+		addSummary(s.namespaceName, "WARNING: log level %s is above debug, the driver logs may be insufficient", logLevel)
+	}
+}
+
+// configParamsLogLevel returns the CSI_LOG_LEVEL of the config params held as YAML in the ConfigMap data
+func configParamsLogLevel(configMap corev1.ConfigMap) string {
+	for _, value := range configMap.Data {
+		params := make(map[string]interface{})
+		if err := yaml.Unmarshal([]byte(value), &params); err != nil {
+			snsLog.Warnf("Parsing ConfigMap %s failed with error: %s", configMap.Name, err.Error())
+			continue
+		}
+		for key, level := range params {
+			if strings.EqualFold(key, "CSI_LOG_LEVEL") {
+				return fmt.Sprintf("%v", level)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package csm
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCollectConfigParams(t *testing.T) {
+	type tests = []struct {
+		description     string
+		configMaps      map[string]string
+		expectedSummary []string
+		expectedFile    bool
+	}
+	var collectConfigParamsTests = tests{
+		{"debug log level", map[string]string{"powerstore-config-params": "CSI_LOG_LEVEL: \"debug\"\nCSI_LOG_FORMAT: \"JSON\"\n"},
+			[]string{"Log level: debug"}, true},
+		{"log level above debug", map[string]string{"powerstore-config-params": "CSI_LOG_LEVEL: \"info\"\n"},
+			[]string{"WARNING: log level info is above debug, the driver logs may be insufficient"}, true},
+		{"log level not set", map[string]string{"powerstore-config-params": "CSI_LOG_FORMAT: \"TEXT\"\n"},
+			[]string{"WARNING: log level unknown, CSI_LOG_LEVEL not set in ConfigMap *-config-params"}, true},
+		{"config params not found", map[string]string{"powerstore-certs": "CSI_LOG_LEVEL: \"debug\"\n"},
+			[]string{"WARNING: log level unknown, ConfigMap *-config-params not found"}, false},
+	}
+	for _, test := range collectConfigParamsTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			for name, params := range test.configMaps {
+				cm := &v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "powerstore"},
+					Data: map[string]string{"driver-config-params.yaml": params}}
+				_, _ = clientset.CoreV1().ConfigMaps("powerstore").Create(context.TODO(), cm, meta_v1.CreateOptions{})
+			}
+			namespaceDirectoryName := createDirectory("config-params-logs")
+			defer os.RemoveAll(namespaceDirectoryName)
+			defer func() { bundleSummary.namespaces = make(map[string][]string) }()
+
+			s := StorageNameSpaceStruct{namespaceName: "powerstore"}
+			s.collectConfigParams(namespaceDirectoryName)
+
+			if diff := cmp.Diff(bundleSummary.namespaces["powerstore"], test.expectedSummary); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedSummary, diff)
+			}
+			_, err := ioutil.ReadFile(namespaceDirectoryName + "/driver-config-params.txt")
+			if diff := cmp.Diff(err == nil, test.expectedFile); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedFile, diff)
+			}
+		})
+	}
+}
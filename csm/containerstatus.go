@@ -0,0 +1,115 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podContainers returns the regular, init and ephemeral containers of the pod
+func podContainers(pod *corev1.Pod) []corev1.Container {
+	var containers []corev1.Container
+	containers = append(containers, pod.Spec.Containers...)
+	containers = append(containers, pod.Spec.InitContainers...)
+	for _, container := range pod.Spec.EphemeralContainers {
+		containers = append(containers, corev1.Container(container.EphemeralContainerCommon))
+	}
+	return containers
+}
+
+// containerType returns whether the pod container is a regular, init or ephemeral container
+func containerType(pod *corev1.Pod, containerName string) string {
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == containerName {
+			return "init"
+		}
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name == containerName {
+			return "ephemeral"
+		}
+	}
+	return "regular"
+}
+
+// getContainerStatus returns the status of the pod container, nil when the container has no status yet
+func getContainerStatus(pod *corev1.Pod, containerName string) *corev1.ContainerStatus {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses, pod.Status.EphemeralContainerStatuses} {
+		for i := range statuses {
+			if statuses[i].Name == containerName {
+				return &statuses[i]
+			}
+		}
+	}
+	return nil
+}
+
+// getRestartCount returns the number of restarts of the pod container
+func getRestartCount(pod *corev1.Pod, containerName string) int32 {
+	if status := getContainerStatus(pod, containerName); status != nil {
+		return status.RestartCount
+	}
+	return 0
+}
+
+// writeContainerSummary records the restart history and the last termination state of the pod container
+func writeContainerSummary(pod *corev1.Pod, containerName string, containerDirectoryName string) {
+	filename := pod.Name + "-" + containerName + "-summary.txt"
+	captureLOG(containerDirectoryName, filename, containerSummary(pod, containerName))
+}
+
+func containerSummary(pod *corev1.Pod, containerName string) string {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Container: %s\n", containerName)
+	if kind := containerType(pod, containerName); kind != "regular" {
+		fmt.Fprintf(&summary, "Type: %s container\n", kind)
+	}
+	status := getContainerStatus(pod, containerName)
+	if status == nil {
+		summary.WriteString("Status: not available\n")
+		return summary.String()
+	}
+	fmt.Fprintf(&summary, "Ready: %t\n", status.Ready)
+	fmt.Fprintf(&summary, "Restart count: %d\n", status.RestartCount)
+	fmt.Fprintf(&summary, "State: %s\n", containerState(status.State))
+	if status.LastTerminationState.Terminated != nil {
+		terminated := status.LastTerminationState.Terminated
+		summary.WriteString("Last termination state:\n")
+		fmt.Fprintf(&summary, "\tExit code: %d\n", terminated.ExitCode)
+		fmt.Fprintf(&summary, "\tReason: %s\n", terminated.Reason)
+		if terminated.Message != "" {
+			fmt.Fprintf(&summary, "\tMessage: %s\n", terminated.Message)
+		}
+		fmt.Fprintf(&summary, "\tStarted at: %s\n", terminated.StartedAt.Format(time.RFC3339))
+		fmt.Fprintf(&summary, "\tFinished at: %s\n", terminated.FinishedAt.Format(time.RFC3339))
+	}
+	return summary.String()
+}
+
+// containerState returns the current state of the container in a single line
+func containerState(state corev1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "Running since " + state.Running.StartedAt.Format(time.RFC3339)
+	case state.Waiting != nil:
+		return strings.TrimSuffix(fmt.Sprintf("Waiting (%s) %s", state.Waiting.Reason, state.Waiting.Message), " ")
+	case state.Terminated != nil:
+		return fmt.Sprintf("Terminated (%s) with exit code %d", state.Terminated.Reason, state.Terminated.ExitCode)
+	}
+	return "unknown"
+}
@@ -0,0 +1,141 @@
+package csm
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func crashedPod(namespace string, name string, containerName string, phase v1.PodPhase) *v1.Pod {
+	finishedAt := meta_v1.NewTime(time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC))
+	return &v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1.PodSpec{Containers: []v1.Container{{Name: containerName}}},
+		Status: v1.PodStatus{Phase: phase, ContainerStatuses: []v1.ContainerStatus{{
+			Name:         containerName,
+			RestartCount: 3,
+			State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+				ExitCode: 1, Reason: "Error", StartedAt: finishedAt, FinishedAt: finishedAt}},
+		}}}}
+}
+
+func TestContainerSummary(t *testing.T) {
+	type tests = []struct {
+		description string
+		pod         *v1.Pod
+		expected    string
+	}
+	var containerSummaryTests = tests{
+		{"crashed container", crashedPod("csi-test", "pod1", "driver", v1.PodRunning),
+			"Container: driver\nReady: false\nRestart count: 3\nState: Waiting (CrashLoopBackOff)\n" +
+				"Last termination state:\n\tExit code: 1\n\tReason: Error\n" +
+				"\tStarted at: 2022-03-01T10:00:00Z\n\tFinished at: 2022-03-01T10:00:00Z\n"},
+		{"container without status", pod("csi-test", "pod1", "image", "driver"), "Container: driver\nStatus: not available\n"},
+	}
+	for _, test := range containerSummaryTests {
+		t.Run(test.description, func(t *testing.T) {
+			if diff := cmp.Diff(containerSummary(test.pod, "driver"), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestPreviousContainerLogs(t *testing.T) {
+	type tests = []struct {
+		description   string
+		phase         v1.PodPhase
+		optionalFlag  string
+		expectedFiles []string
+	}
+	var previousContainerLogsTests = tests{
+		{"restarted running pod", v1.PodRunning, "true", []string{"pod1-driver.txt", "pod1-driver-previous.txt", "pod1-driver-summary.txt"}},
+		{"restarted running pod without optional logs", v1.PodRunning, "false", []string{"pod1-driver-previous.txt", "pod1-driver-summary.txt"}},
+		{"crashed non-running pod", v1.PodFailed, "", []string{"pod1.txt", "pod1-driver.txt", "pod1-driver-previous.txt", "pod1-driver-summary.txt"}},
+	}
+	for _, test := range previousContainerLogsTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			pod, _ := clientset.CoreV1().Pods("csi-test").Create(context.TODO(), crashedPod("csi-test", "pod1", "driver", test.phase), meta_v1.CreateOptions{})
+			namespaceDirectoryName := createDirectory("previous-logs")
+			defer os.RemoveAll(namespaceDirectoryName)
+
+			st := StorageNameSpaceStruct{namespaceName: "csi-test"}
+			if test.phase == v1.PodRunning {
+				st.GetRunningPods(namespaceDirectoryName, pod, nil, test.optionalFlag)
+			} else {
+				st.GetNonRunningPods(namespaceDirectoryName, pod)
+			}
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(namespaceDirectoryName + "/pod1/driver/" + file); err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPreviousLogsAfterFailedCurrentLogs(t *testing.T) {
+	t.Run("previous logs collected when the current logs fail", func(t *testing.T) {
+		clientset = fake.NewSimpleClientset()
+		pod, _ := clientset.CoreV1().Pods("csi-test").Create(context.TODO(), crashedPod("csi-test", "pod1", "driver", v1.PodRunning), meta_v1.CreateOptions{})
+		namespaceDirectoryName := createDirectory("failed-logs")
+		defer os.RemoveAll(namespaceDirectoryName)
+		// the current log file cannot be created over a directory
+		createDirectory(namespaceDirectoryName + "/pod1/driver/pod1-driver.txt")
+
+		st := StorageNameSpaceStruct{namespaceName: "csi-test"}
+		pool := newWorkerPool()
+		st.getRunningPods(st, pool, namespaceDirectoryName, pod, nil, "true")
+		if err := pool.Wait(); err == nil {
+			t.Errorf("error expected for the current logs")
+		}
+		if _, err := os.Stat(namespaceDirectoryName + "/pod1/driver/pod1-driver-previous.txt"); err != nil {
+			t.Errorf("previous logs not collected: %s", err)
+		}
+	})
+}
+
+func TestInitAndEphemeralContainerLogs(t *testing.T) {
+	type tests = []struct {
+		description     string
+		expectedFiles   []string
+		expectedSummary string
+	}
+	var initAndEphemeralContainerLogsTests = tests{
+		{"init and ephemeral container logs collected",
+			[]string{"driver/pod1-driver.txt", "sdc/pod1-sdc.txt", "sdc/pod1-sdc-summary.txt", "debugger/pod1-debugger.txt"},
+			"Container: sdc\nType: init container\nReady: false\nRestart count: 0\nState: Terminated (Completed) with exit code 0\n"},
+	}
+	for _, test := range initAndEphemeralContainerLogsTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			pod := &v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Name: "pod1", Namespace: "csi-test"},
+				Spec: v1.PodSpec{Containers: []v1.Container{{Name: "driver"}},
+					InitContainers:      []v1.Container{{Name: "sdc"}},
+					EphemeralContainers: []v1.EphemeralContainer{{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debugger"}}}},
+				Status: v1.PodStatus{Phase: v1.PodRunning, InitContainerStatuses: []v1.ContainerStatus{{Name: "sdc",
+					State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}}}}}}
+			pod, _ = clientset.CoreV1().Pods("csi-test").Create(context.TODO(), pod, meta_v1.CreateOptions{})
+			namespaceDirectoryName := createDirectory("init-logs")
+			defer os.RemoveAll(namespaceDirectoryName)
+
+			st := StorageNameSpaceStruct{namespaceName: "csi-test"}
+			st.GetRunningPods(namespaceDirectoryName, pod, nil, "true")
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(namespaceDirectoryName + "/pod1/" + file); err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+				}
+			}
+			if diff := cmp.Diff(containerSummary(pod, "sdc"), test.expectedSummary); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedSummary, diff)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -108,7 +109,7 @@ func TestGetDriverDetails(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.description, func(t *testing.T) {
 			clientset = fake.NewSimpleClientset(test.objs...)
-			gotNamespace, _, _ := st.GetDriverDetails("csi-powerstore", 3)
+			gotNamespace, _, _ := st.GetDriverDetails("csi-powerstore", "powerstore")
 			if diff := cmp.Diff(gotNamespace, test.expectedNamespace); diff != "" {
 				t.Errorf("%T differ (-got, +want): %s", test.expectedNamespace, diff)
 				return
@@ -286,7 +287,7 @@ func TestGetNonRunningPods(t *testing.T) {
 	// PowerFlex
 	var pflx PowerFlexStruct
 	var pflxTests = tests{
-		{"get non running pod logs", "vxflexos-namespace", "Pod status: "},
+		{"get non running pod logs", "vxflexos-namespace", "Pod status: not running"},
 	}
 
 	for _, test := range pflxTests {
@@ -309,7 +310,7 @@ func TestGetNonRunningPods(t *testing.T) {
 	// PowerMax
 	var pmx PowerMaxStruct
 	var pmxTests = tests{
-		{"get non running pod logs", "powermax-namespace", "Pod status: "},
+		{"get non running pod logs", "powermax-namespace", "Pod status: not running"},
 	}
 
 	for _, test := range pmxTests {
@@ -432,7 +433,7 @@ func TestPowermaxLogs(t *testing.T) {
 			_ = CreatePod(clientset, test.namespace, test.podName, "attacher")
 			_ = CreateLease(clientset, test.leaseName, test.namespace, test.podName)
 			daysCount := -1
-			pmax.GetLogs(test.namespace, "true", daysCount, 4)
+			pmax.GetLogs(test.namespace, "true", daysCount, "powermax")
 			files, _ := ioutil.ReadDir(currentPath)
 			for _, file := range files {
 				if strings.Contains(file.Name(), test.namespace) {
@@ -471,7 +472,7 @@ func TestPowerscaleLogs(t *testing.T) {
 			_ = CreatePod(clientset, test.namespace, test.podName, "attacher")
 			_ = CreateLease(clientset, test.leaseName, test.namespace, test.podName)
 			daysCount := -1
-			pscale.GetLogs(test.namespace, "true", daysCount, 1)
+			pscale.GetLogs(test.namespace, "true", daysCount, "powerscale")
 			files, _ := ioutil.ReadDir(currentPath)
 			for _, file := range files {
 				if strings.Contains(file.Name(), test.namespace) {
@@ -511,7 +512,7 @@ func TestPowerflexLogs(t *testing.T) {
 			_ = CreatePod(clientset, test.namespace, test.podName, "attacher")
 			_ = CreateLease(clientset, test.leaseName, test.namespace, test.podName)
 			daysCount := -1
-			pflx.GetLogs(test.namespace, "true", daysCount, 5)
+			pflx.GetLogs(test.namespace, "true", daysCount, "powerflex")
 			files, _ := ioutil.ReadDir(currentPath)
 			for _, file := range files {
 				if strings.Contains(file.Name(), test.namespace) {
@@ -551,7 +552,7 @@ func TestUnityLogs(t *testing.T) {
 			_ = CreatePod(clientset, test.namespace, test.podName, "attacher")
 			_ = CreateLease(clientset, test.leaseName, test.namespace, test.podName)
 			daysCount := -1
-			unity.GetLogs(test.namespace, "true", daysCount, 2)
+			unity.GetLogs(test.namespace, "true", daysCount, "unity")
 			files, _ := ioutil.ReadDir(currentPath)
 			for _, file := range files {
 				if strings.Contains(file.Name(), test.namespace) {
@@ -590,7 +591,7 @@ func TestPowerstoreLogs(t *testing.T) {
 			_ = CreatePod(clientset, test.namespace, test.podName, "attacher")
 			_ = CreateLease(clientset, test.leaseName, test.namespace, test.podName)
 			daysCount := -1
-			pstore.GetLogs(test.namespace, "true", daysCount, 3)
+			pstore.GetLogs(test.namespace, "true", daysCount, "powerstore")
 			files, _ := ioutil.ReadDir(currentPath)
 			for _, file := range files {
 				if strings.Contains(file.Name(), test.namespace) {
@@ -688,3 +689,122 @@ func TestCaptureLOG(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectBundle(t *testing.T) {
+	type tests = []struct {
+		description   string
+		namespaces    []string
+		targets       []CollectionTarget
+		expectedFiles []string
+	}
+	var collectBundleTests = tests{
+		{"collect logs of multiple drivers into single bundle",
+			[]string{"csi-powerstore", "csi-powerscale"},
+			[]CollectionTarget{{Driver: PowerStoreStruct{}, Namespace: "csi-powerstore", DriverName: "powerstore"},
+				{Driver: PowerScaleStruct{}, Namespace: "csi-powerscale", DriverName: "powerscale"}},
+			[]string{
+				"cluster/nodes/10.xx.xxx.xxx/10.xx.xxx.xxx-describe.txt",
+				"csi-powerstore/pod1/pod1-describe.txt",
+				"csi-powerscale/pod1/pod1-describe.txt",
+			}},
+		{"collect drivers of the same namespace into a subtree per driver",
+			[]string{"csi-dell"},
+			[]CollectionTarget{{Driver: PowerStoreStruct{}, Namespace: "csi-dell", DriverName: "powerstore"},
+				{Driver: PowerScaleStruct{}, Namespace: "csi-dell", DriverName: "powerscale"}},
+			[]string{
+				"csi-dell/powerstore/pod1/pod1-describe.txt",
+				"csi-dell/powerscale/pod1/pod1-describe.txt",
+			}},
+	}
+	for _, test := range collectBundleTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			_ = CreateNodes(clientset, "10.xx.xxx.xxx")
+			for _, namespace := range test.namespaces {
+				_ = CreateNamespace(clientset, namespace)
+				_ = CreatePod(clientset, namespace, "pod1", "attacher")
+			}
+			// the bundles left by earlier runs are not the one of this run
+			existing := make(map[string]bool)
+			previousBundles, _ := filepath.Glob("csm-logs_*.tar.gz")
+			for _, bundle := range previousBundles {
+				existing[bundle] = true
+			}
+			CollectBundle(test.targets, "true", -1)
+			allBundles, _ := filepath.Glob("csm-logs_*.tar.gz")
+			var bundles []string
+			for _, bundle := range allBundles {
+				if !existing[bundle] {
+					bundles = append(bundles, bundle)
+				}
+			}
+			for _, bundle := range bundles {
+				defer os.RemoveAll(strings.TrimSuffix(bundle, ".tar.gz"))
+				defer os.Remove(bundle)
+			}
+			if len(bundles) != 1 {
+				t.Errorf("single bundle expected, got: %s", bundles)
+				return
+			}
+			bundleDirectoryName := strings.TrimSuffix(bundles[0], ".tar.gz")
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(filepath.Join(bundleDirectoryName, file)); err != nil {
+					t.Errorf("file %s not collected in the bundle: %s", file, err)
+				}
+			}
+		})
+	}
+}
+
+// hookRecorder records the collection hooks called by the pipeline
+type hookRecorder struct {
+	StorageNameSpaceStruct
+}
+
+var recordedHooks []string
+
+func (h hookRecorder) PreCollect(s StorageNameSpaceStruct, namespaceDirectoryName string) {
+	recordedHooks = append(recordedHooks, "pre-collect")
+}
+
+func (h hookRecorder) PerPod(s StorageNameSpaceStruct, pod *v1.Pod, podDirectoryName string) {
+	recordedHooks = append(recordedHooks, "pod/"+pod.Name)
+}
+
+func (h hookRecorder) PerContainer(s StorageNameSpaceStruct, pod *v1.Pod, container v1.Container, containerDirectoryName string) {
+	recordedHooks = append(recordedHooks, "container/"+container.Name)
+}
+
+func (h hookRecorder) PostCollect(s StorageNameSpaceStruct, namespaceDirectoryName string) {
+	recordedHooks = append(recordedHooks, "post-collect")
+}
+
+func TestCollectionHooks(t *testing.T) {
+	type tests = []struct {
+		description string
+		namespace   string
+		expected    []string
+	}
+	var hookTests = tests{
+		{"collection hooks called in order", "csi-hooktest", []string{"pre-collect", "pod/pod1", "container/driver", "post-collect"}},
+	}
+	RegisterDriver(Driver{Name: "hooktest", DisplayName: "Hook Test", ImagePatterns: []string{"csi-hooktest"},
+		New: func() StorageNameSpace { return hookRecorder{} }})
+	defer delete(driverRegistry, "hooktest")
+
+	for _, test := range hookTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			recordedHooks = nil
+			_ = CreatePod(clientset, test.namespace, "pod1", "driver")
+			namespaceDirectoryName := createDirectory("hooktest-logs")
+			defer os.RemoveAll(namespaceDirectoryName)
+
+			var st StorageNameSpaceStruct
+			st.CollectLogs(test.namespace, namespaceDirectoryName, "false", nil, "hooktest")
+			if diff := cmp.Diff(recordedHooks, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// DefaultDebugImage is the image of the node debug pods, it only needs chroot and sh
+	DefaultDebugImage = "busybox"
+	// debugPodHostRoot is the mount path of the root file system of the node in the debug pod
+	debugPodHostRoot = "/host"
+	// debugPodLifetime bounds the lifetime of a debug pod which could not be deleted
+	debugPodLifetime = 3600
+	// debugPodSelector selects the debug pods, including those whose creation was not tracked yet
+	debugPodSelector = "app=csm-logcollector-debug"
+)
+
+// debugPodTimeout is the time given to a debug pod to be running
+var debugPodTimeout = 2 * time.Minute
+
+// debugPodCommandTimeout is the time given to a command run in a debug pod
+var debugPodCommandTimeout = 10 * time.Minute
+
+// debugPods holds the namespace of the node debug pods not deleted yet, by pod name
+var debugPods = struct {
+	sync.Mutex
+	pods map[string]string
+}{pods: make(map[string]string)}
+
+// debugPodExecutor runs the commands in a privileged pod scheduled on the node,
+// chrooted into the root file system of the node
+type debugPodExecutor struct {
+	namespace string
+	name      string
+}
+
+// newDebugPodExecutor creates a privileged debug pod on the node and waits for it to be running
+func newDebugPodExecutor(node corev1.Node, namespace string) (nodeExecutor, error) {
+	privileged := true
+	activeDeadlineSeconds := int64(debugPodLifetime)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "csm-logcollector-debug-",
+			Namespace:    namespace,
+			Labels:       map[string]string{"app": "csm-logcollector-debug"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:              node.Name,
+			HostPID:               true,
+			HostNetwork:           true,
+			RestartPolicy:         corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Tolerations:           []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:            "debug",
+				Image:           nodeDiagnostics.DebugImage,
+				Command:         []string{"sleep", fmt.Sprint(debugPodLifetime)},
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+				VolumeMounts:    []corev1.VolumeMount{{Name: "host-root", MountPath: debugPodHostRoot}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         "host-root",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/"}},
+			}},
+		},
+	}
+	pod, err := clientset.CoreV1().Pods(namespace).Create(context.TODO(), pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("creating debug pod on node %s failed with error: %s", node.Name, err.Error())
+	}
+	debugPods.Lock()
+	debugPods.pods[pod.Name] = namespace
+	debugPods.Unlock()
+	executor := debugPodExecutor{namespace: namespace, name: pod.Name}
+
+	err = wait.PollImmediate(time.Second, debugPodTimeout, func() (bool, error) {
+		pod, err := clientset.CoreV1().Pods(namespace).Get(context.TODO(), executor.name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+			return false, fmt.Errorf("debug pod %s is %s", executor.name, pod.Status.Phase)
+		}
+		return pod.Status.Phase == corev1.PodRunning, nil
+	})
+	if err != nil {
+		_ = executor.Close()
+		return nil, fmt.Errorf("starting debug pod on node %s failed with error: %s", node.Name, err.Error())
+	}
+	return executor, nil
+}
+
+// Run executes the command on the node through the exec API of the debug pod, the output is streamed
+// to the writer until the command completes or the command timeout expires
+func (e debugPodExecutor) Run(command string, output io.Writer) error {
+	if restConfig == nil {
+		return fmt.Errorf("client configuration is not available to run commands in debug pod %s", e.name)
+	}
+	request := clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(e.namespace).Name(e.name).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: "debug",
+			Command:   []string{"chroot", debugPodHostRoot, "sh", "-c", command},
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(restConfig, "POST", request.URL())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), debugPodCommandTimeout)
+	defer cancel()
+	stream := &stoppableWriter{writer: output}
+	done := make(chan error, 1)
+	go func() {
+		done <- stream.copy(exec)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// the stream still running is detached from the output, it ends with the deletion of the debug pod
+		stream.stop()
+		return fmt.Errorf("command timed out after %s", debugPodCommandTimeout)
+	}
+}
+
+// stoppableWriter discards the writes of a stream once stopped, so that the output can be closed
+// while the stream is still running
+type stoppableWriter struct {
+	sync.Mutex
+	writer  io.Writer
+	stopped bool
+}
+
+func (w *stoppableWriter) copy(exec remotecommand.Executor) error {
+	return exec.Stream(remotecommand.StreamOptions{Stdout: w, Stderr: w})
+}
+
+func (w *stoppableWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	if w.stopped {
+		return 0, io.ErrClosedPipe
+	}
+	return w.writer.Write(p)
+}
+
+func (w *stoppableWriter) stop() {
+	w.Lock()
+	w.stopped = true
+	w.Unlock()
+}
+
+// Close deletes the debug pod
+func (e debugPodExecutor) Close() error {
+	gracePeriodSeconds := int64(0)
+	err := clientset.CoreV1().Pods(e.namespace).Delete(context.TODO(), e.name, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds})
+	if err != nil {
+		return fmt.Errorf("deleting debug pod %s/%s failed with error: %s", e.namespace, e.name, err.Error())
+	}
+	debugPods.Lock()
+	delete(debugPods.pods, e.name)
+	debugPods.Unlock()
+	return nil
+}
+
+// cleanupDebugPods deletes the debug pods left behind, e.g. when the application exits on an error or an interrupt,
+// the pods are also selected by label in case their creation was in flight
+func cleanupDebugPods() {
+	debugPods.Lock()
+	pods := make(map[string]string)
+	for name, namespace := range debugPods.pods {
+		pods[name] = namespace
+	}
+	debugPods.Unlock()
+	namespace := debugPodNamespace()
+	labeled, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: debugPodSelector})
+	if err != nil {
+		fmt.Printf("\tListing debug pods failed, please delete the pods labeled %s in namespace %s manually\n", debugPodSelector, namespace)
+		snsLog.Errorf("listing debug pods failed with error: %s", err.Error())
+	} else {
+		for _, pod := range labeled.Items {
+			pods[pod.Name] = pod.Namespace
+		}
+	}
+	var executors []debugPodExecutor
+	for name, namespace := range pods {
+		executors = append(executors, debugPodExecutor{namespace: namespace, name: name})
+	}
+	for _, executor := range executors {
+		if err := executor.Close(); err != nil {
+			fmt.Printf("\tDeleting debug pod failed, please delete pod %s/%s manually\n", executor.namespace, executor.name)
+			snsLog.Errorf("%s", err.Error())
+		}
+	}
+}
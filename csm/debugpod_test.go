@@ -0,0 +1,104 @@
+package csm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestDebugPodExecutor(t *testing.T) {
+	type tests = []struct {
+		description string
+		phase       v1.PodPhase
+		expectedErr bool
+	}
+	var debugPodExecutorTests = tests{
+		{"debug pod running", v1.PodRunning, false},
+		{"debug pod failed", v1.PodFailed, true},
+	}
+	SetNodeDiagnostics(NodeDiagnostics{Enabled: true, DebugPods: true})
+	defer SetNodeDiagnostics(NodeDiagnostics{})
+	for _, test := range debugPodExecutorTests {
+		t.Run(test.description, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			clientset = client
+			// the fake clientset neither generates the names nor schedules the pods
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				pod := action.(k8stesting.CreateAction).GetObject().(*v1.Pod)
+				pod.Name = pod.GenerateName + "abcde"
+				pod.Status.Phase = test.phase
+				return false, nil, nil
+			})
+			node := CreateNodes(clientset, "worker-1")
+
+			executor, err := newDebugPodExecutor(*node, "csi-powerstore")
+			if diff := cmp.Diff(err != nil, test.expectedErr); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedErr, diff)
+			}
+			if err == nil {
+				pod, err := clientset.CoreV1().Pods("csi-powerstore").Get(context.TODO(), "csm-logcollector-debug-abcde", meta_v1.GetOptions{})
+				if err != nil {
+					t.Fatalf("debug pod not created: %s", err)
+				}
+				if pod.Spec.NodeName != "worker-1" || !pod.Spec.HostPID || !pod.Spec.HostNetwork || !*pod.Spec.Containers[0].SecurityContext.Privileged {
+					t.Errorf("debug pod is not a privileged host pod of the node: %+v", pod.Spec)
+				}
+				_ = executor.Close()
+			}
+			pods, _ := clientset.CoreV1().Pods("csi-powerstore").List(context.TODO(), meta_v1.ListOptions{})
+			if len(pods.Items) != 0 {
+				t.Errorf("debug pods not deleted: %d", len(pods.Items))
+			}
+		})
+	}
+}
+
+func TestCleanupDebugPods(t *testing.T) {
+	t.Run("debug pods left behind are deleted", func(t *testing.T) {
+		clientset = fake.NewSimpleClientset()
+		_ = CreatePod(clientset, "csi-powerstore", "csm-logcollector-debug-abcde", "debug")
+		debugPods.pods["csm-logcollector-debug-abcde"] = "csi-powerstore"
+
+		cleanupDebugPods()
+
+		pods, _ := clientset.CoreV1().Pods("csi-powerstore").List(context.TODO(), meta_v1.ListOptions{})
+		if len(pods.Items) != 0 || len(debugPods.pods) != 0 {
+			t.Errorf("debug pods not deleted: %d, %v", len(pods.Items), debugPods.pods)
+		}
+	})
+
+	t.Run("debug pods not tracked yet are deleted by label", func(t *testing.T) {
+		clientset = fake.NewSimpleClientset()
+		pod := &v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Name: "csm-logcollector-debug-fghij", Namespace: debugPodNamespace(),
+			Labels: map[string]string{"app": "csm-logcollector-debug"}}}
+		_, _ = clientset.CoreV1().Pods(pod.Namespace).Create(context.TODO(), pod, meta_v1.CreateOptions{})
+		_ = CreatePod(clientset, debugPodNamespace(), "driver-pod", "driver")
+
+		cleanupDebugPods()
+
+		pods, _ := clientset.CoreV1().Pods(debugPodNamespace()).List(context.TODO(), meta_v1.ListOptions{})
+		if len(pods.Items) != 1 || pods.Items[0].Name != "driver-pod" {
+			t.Errorf("only the debug pods should be deleted: %v", pods.Items)
+		}
+	})
+}
+
+func TestStoppableWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &stoppableWriter{writer: &buf}
+	_, _ = w.Write([]byte("before timeout\n"))
+	w.stop()
+	if _, err := w.Write([]byte("after timeout\n")); err == nil {
+		t.Errorf("write expected to fail once the writer is stopped")
+	}
+	if diff := cmp.Diff(buf.String(), "before timeout\n"); diff != "" {
+		t.Errorf("%T differ (-got, +want): %s", "", diff)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"context"
+	utils "csm-logcollector/utils"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Logging object
+var discoveryLog, _ = utils.GetLogger()
+
+// DriverInstall holds the details of a CSI driver installation found in the cluster
+type DriverInstall struct {
+	Platform    string
+	Driver      string
+	Namespace   string
+	DriverName  string
+	Version     string
+	Provisioner string
+	Workloads   []string
+}
+
+// DiscoverDrivers lists the Dell CSI drivers installed in all the namespaces of the cluster,
+// an error is returned when the cluster wide objects cannot be listed, e.g. with namespace scoped RBAC
+func DiscoverDrivers() ([]DriverInstall, error) {
+	fmt.Println("\n\nDiscovering CSI drivers..............")
+	fmt.Println("=====================================")
+	installs := make(map[string]*DriverInstall)
+	getInstall := func(namespace string, driver Driver) *DriverInstall {
+		key := namespace + "/" + driver.Name
+		if _, ok := installs[key]; !ok {
+			installs[key] = &DriverInstall{Platform: driver.DisplayName, Driver: driver.Name, Namespace: namespace}
+		}
+		return installs[key]
+	}
+	addWorkload := func(kind string, meta metav1.ObjectMeta, containers []corev1.Container) {
+		for _, container := range containers {
+			driver, ok := driverForImage(container.Image)
+			if !ok {
+				continue
+			}
+			install := getInstall(meta.Namespace, driver)
+			install.Workloads = append(install.Workloads, kind+"/"+meta.Name)
+			splitString := strings.SplitN(container.Image, ":", 2)
+			install.DriverName = splitString[0]
+			if len(splitString) > 1 {
+				install.Version = splitString[1]
+			}
+			break
+		}
+	}
+
+	daemonSets, err := clientset.AppsV1().DaemonSets("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting daemonsets failed with error: %w", err)
+	}
+	for _, ds := range daemonSets.Items {
+		addWorkload("DaemonSet", ds.ObjectMeta, ds.Spec.Template.Spec.Containers)
+	}
+
+	deployments, err := clientset.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting deployments failed with error: %w", err)
+	}
+	for _, deployment := range deployments.Items {
+		addWorkload("Deployment", deployment.ObjectMeta, deployment.Spec.Template.Spec.Containers)
+	}
+
+	// driver leases point to the namespace of installs whose workloads are not found, e.g. scaled down
+	leases, err := clientset.CoordinationV1().Leases("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting leases failed with error: %w", err)
+	}
+	leaseNames := make(map[string][]string)
+	for _, lease := range leases.Items {
+		if !strings.Contains(lease.Name, "dellemc-com") {
+			continue
+		}
+		if driver, ok := driverForName(lease.Name); ok {
+			getInstall(lease.Namespace, driver)
+			leaseNames[lease.Namespace] = append(leaseNames[lease.Namespace], lease.Name)
+		}
+	}
+
+	csiDrivers, err := clientset.StorageV1().CSIDrivers().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting CSI drivers failed with error: %w", err)
+	}
+	for _, csiDriver := range csiDrivers.Items {
+		driver, ok := driverForName(csiDriver.Name)
+		if !ok {
+			continue
+		}
+		var candidates []*DriverInstall
+		for _, install := range installs {
+			if install.Driver == driver.Name && install.Provisioner == "" {
+				candidates = append(candidates, install)
+			}
+		}
+		assigned := false
+		for _, install := range candidates {
+			for _, name := range leaseNames[install.Namespace] {
+				if leaseMatchesProvisioner(name, csiDriver.Name) {
+					install.Provisioner = csiDriver.Name
+					assigned = true
+				}
+			}
+		}
+		if !assigned && len(candidates) == 1 {
+			candidates[0].Provisioner = csiDriver.Name
+			assigned = true
+		}
+		if !assigned && len(candidates) == 0 {
+			discoveryLog.Warnf("No namespace found for CSI driver %s", csiDriver.Name)
+			install := getInstall("", driver)
+			install.Provisioner = csiDriver.Name
+		}
+	}
+
+	var driverInstalls []DriverInstall
+	for _, install := range installs {
+		driverInstalls = append(driverInstalls, *install)
+	}
+	sort.Slice(driverInstalls, func(i, j int) bool {
+		if driverInstalls[i].Namespace != driverInstalls[j].Namespace {
+			return driverInstalls[i].Namespace < driverInstalls[j].Namespace
+		}
+		return driverInstalls[i].Platform < driverInstalls[j].Platform
+	})
+	discoveryLog.Infof("Discovered CSI drivers: %+v", driverInstalls)
+	return driverInstalls, nil
+}
+
+// PrintDriverInstalls prints the discovered CSI driver installations
+func PrintDriverInstalls(driverInstalls []DriverInstall) {
+	if len(driverInstalls) == 0 {
+		fmt.Println("\tNo Dell CSI driver found in the cluster")
+		return
+	}
+	for i, install := range driverInstalls {
+		namespace := install.Namespace
+		if namespace == "" {
+			namespace = "<not found>"
+		}
+		version := install.Version
+		if version == "" {
+			version = "<unknown>"
+		}
+		fmt.Printf("%d. %s\n", i+1, install.Platform)
+		fmt.Printf("\tNamespace: \t%s\n", namespace)
+		fmt.Printf("\tDriver version: %s\n", version)
+		if install.Provisioner != "" {
+			fmt.Printf("\tProvisioner: \t%s\n", install.Provisioner)
+		}
+		if len(install.Workloads) > 0 {
+			fmt.Printf("\tWorkloads: \t%s\n", strings.Join(install.Workloads, ", "))
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package csm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func CreateDaemonSet(clientset kubernetes.Interface, namespace string, name string, image string) *appsv1.DaemonSet {
+	ds := &appsv1.DaemonSet{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DaemonSetSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{Name: "driver", Image: image}}}}}}
+	resp, _ := clientset.AppsV1().DaemonSets(namespace).Create(context.TODO(), ds, meta_v1.CreateOptions{})
+	return resp
+}
+
+func CreateCSIDriver(clientset kubernetes.Interface, name string) *storagev1.CSIDriver {
+	csiDriver := &storagev1.CSIDriver{ObjectMeta: meta_v1.ObjectMeta{Name: name}}
+	resp, _ := clientset.StorageV1().CSIDrivers().Create(context.TODO(), csiDriver, meta_v1.CreateOptions{})
+	return resp
+}
+
+func TestDiscoverDrivers(t *testing.T) {
+	type tests = []struct {
+		description string
+		expected    []DriverInstall
+	}
+
+	var discoveryTests = tests{
+		{"discover drivers in all namespaces",
+			[]DriverInstall{
+				{Platform: "PowerMax", Driver: "powermax", Namespace: "powermax", DriverName: "dellemc/csi-powermax", Version: "v2.2.0",
+					Provisioner: "csi-powermax.dellemc.com", Workloads: []string{"DaemonSet/powermax-node"}},
+				{Platform: "Unity", Driver: "unity", Namespace: "unity"},
+			}},
+	}
+
+	for _, test := range discoveryTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			_ = CreateDaemonSet(clientset, "powermax", "powermax-node", "dellemc/csi-powermax:v2.2.0")
+			_ = CreateDaemonSet(clientset, "default", "other-node", "nginx:latest")
+			_ = CreateCSIDriver(clientset, "csi-powermax.dellemc.com")
+			_ = CreateLease(clientset, "driver-csi-unity-dellemc-com", "unity", "unity-controller")
+			actual, err := DiscoverDrivers()
+			if err != nil {
+				t.Fatalf("discovery failed: %s", err)
+			}
+			if diff := cmp.Diff(actual, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+				return
+			}
+		})
+	}
+}
+
+func TestDiscoverDriversForbidden(t *testing.T) {
+	type tests = []struct {
+		description string
+		resource    string
+	}
+	var forbiddenTests = tests{
+		{"daemonsets forbidden", "daemonsets"},
+		{"leases forbidden", "leases"},
+		{"csidrivers forbidden", "csidrivers"},
+	}
+	for _, test := range forbiddenTests {
+		t.Run(test.description, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("list", test.resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: test.resource}, "", nil)
+			})
+			clientset = client
+			installs, err := DiscoverDrivers()
+			if !apierrors.IsForbidden(err) {
+				t.Errorf("forbidden error expected, got: %v", err)
+			}
+			if len(installs) != 0 {
+				t.Errorf("no driver expected, got: %+v", installs)
+			}
+		})
+	}
+}
+
+func TestDriverForImage(t *testing.T) {
+	type tests = []struct {
+		description      string
+		image            string
+		expectedPlatform string
+	}
+
+	var imageTests = tests{
+		{"powerscale image", "dellemc/csi-isilon:v2.2.0", "PowerScale"},
+		{"powerflex image with registry port", "registry:5000/dellemc/csi-vxflexos:v2.2.0", "PowerFlex"},
+		{"reverseproxy image", "dellemc/csipowermax-reverseproxy:v1.4.0", ""},
+		{"non dell image", "k8s.gcr.io/sig-storage/csi-attacher:v3.4.0", ""},
+	}
+
+	for _, test := range imageTests {
+		t.Run(test.description, func(t *testing.T) {
+			driver, _ := driverForImage(test.image)
+			if diff := cmp.Diff(driver.DisplayName, test.expectedPlatform); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedPlatform, diff)
+				return
+			}
+		})
+	}
+}
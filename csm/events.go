@@ -0,0 +1,159 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// collectEvents writes the events of the driver namespace and of the volumes of the driver provisioner,
+// i.e. the PVCs of its storage classes, the PVs and the volume attachments, in text and JSON
+func (s StorageNameSpaceStruct) collectEvents(namespaceDirectoryName string, dateRange *metav1.Time) {
+	fmt.Println("\n\nCollecting events..........")
+	events, err := s.getEvents(dateRange)
+	if err != nil {
+		reportErrors("Collecting events of namespace "+s.namespaceName, err)
+		return
+	}
+	fmt.Printf("\t%d events collected\n", len(events))
+
+	eventsDirectoryName := createDirectory(namespaceDirectoryName + "/events")
+	eventList := corev1.EventList{TypeMeta: metav1.TypeMeta{Kind: "EventList", APIVersion: "v1"}, Items: events}
+	data, err := json.MarshalIndent(eventList, "", "  ")
+	if err != nil {
+		reportErrors("Converting events to JSON", err)
+		return
+	}
+	sensitiveContentList := getSensitiveContent(s.namespaceName)
+	if err := writeSanitized(eventsDirectoryName, "events.json", data, sensitiveContentList); err != nil {
+		reportErrors("Writing events", err)
+	}
+	if err := writeSanitized(eventsDirectoryName, "events.txt", formatEvents(events), sensitiveContentList); err != nil {
+		reportErrors("Writing events", err)
+	}
+}
+
+// getEvents returns the events of the driver sorted by time, the events older than the date range are skipped
+func (s StorageNameSpaceStruct) getEvents(dateRange *metav1.Time) ([]corev1.Event, error) {
+	volumeObjects, err := s.getVolumeObjects()
+	if err != nil {
+		return nil, err
+	}
+	eventList, err := clientset.CoreV1().Events("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting events failed with error: %s", err.Error())
+	}
+	var events []corev1.Event
+	for _, event := range eventList.Items {
+		object := event.InvolvedObject
+		if object.Namespace != s.namespaceName && !volumeObjects[object.Kind+"/"+object.Namespace+"/"+object.Name] {
+			continue
+		}
+		if dateRange != nil && !dateRange.IsZero() && eventTime(event).Before(dateRange.Time) {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	return events, nil
+}
+
+// getVolumeObjects returns the PVCs, PVs and volume attachments of the driver provisioner keyed by kind/namespace/name
+func (s StorageNameSpaceStruct) getVolumeObjects() (map[string]bool, error) {
+	volumeObjects := make(map[string]bool)
+	if s.provisioner == "" {
+		return volumeObjects, nil
+	}
+
+	storageClasses, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting storage classes failed with error: %s", err.Error())
+	}
+	storageClassNames := make(map[string]bool)
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Provisioner == s.provisioner {
+			storageClassNames[storageClass.Name] = true
+		}
+	}
+
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting persistent volume claims failed with error: %s", err.Error())
+	}
+	for _, pvc := range pvcs.Items {
+		if pvc.Spec.StorageClassName != nil && storageClassNames[*pvc.Spec.StorageClassName] {
+			volumeObjects["PersistentVolumeClaim/"+pvc.Namespace+"/"+pvc.Name] = true
+		}
+	}
+
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting persistent volumes failed with error: %s", err.Error())
+	}
+	for _, pv := range pvs.Items {
+		if pv.Spec.CSI != nil && pv.Spec.CSI.Driver == s.provisioner {
+			volumeObjects["PersistentVolume//"+pv.Name] = true
+		}
+	}
+
+	volumeAttachments, err := clientset.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting volume attachments failed with error: %s", err.Error())
+	}
+	for _, volumeAttachment := range volumeAttachments.Items {
+		if volumeAttachment.Spec.Attacher == s.provisioner {
+			volumeObjects["VolumeAttachment//"+volumeAttachment.Name] = true
+		}
+	}
+	return volumeObjects, nil
+}
+
+// eventTime returns the time the event was last seen
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// formatEvents returns the events as a table similar to kubectl get events
+func formatEvents(events []corev1.Event) []byte {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tNAMESPACE\tTYPE\tREASON\tOBJECT\tCOUNT\tMESSAGE")
+	for _, event := range events {
+		object := strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", eventTime(event).Format(time.RFC3339), event.InvolvedObject.Namespace,
+			event.Type, event.Reason, object, event.Count, strings.TrimSpace(event.Message))
+	}
+	w.Flush()
+	return buf.Bytes()
+}
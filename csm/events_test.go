@@ -0,0 +1,87 @@
+package csm
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func CreateEvent(clientset *fake.Clientset, namespace string, name string, object v1.ObjectReference, lastSeen time.Time) {
+	event := &v1.Event{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: namespace}, InvolvedObject: object,
+		Reason: "Test", Message: name, LastTimestamp: meta_v1.NewTime(lastSeen)}
+	_, _ = clientset.CoreV1().Events(namespace).Create(context.TODO(), event, meta_v1.CreateOptions{})
+}
+
+func TestGetEvents(t *testing.T) {
+	now := time.Now()
+	type tests = []struct {
+		description string
+		dateRange   *meta_v1.Time
+		expected    []string
+	}
+	since := meta_v1.NewTime(now.Add(-2 * time.Hour))
+	var getEventsTests = tests{
+		{"driver and volume events sorted by time", nil, []string{"old-pod-event", "pvc-event", "pv-event", "attach-event", "pod-event"}},
+		{"events within date range", &since, []string{"pvc-event", "pv-event", "attach-event", "pod-event"}},
+	}
+	for _, test := range getEventsTests {
+		t.Run(test.description, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			clientset = client
+			storageClassName := "powerstore"
+			sc := &storagev1.StorageClass{ObjectMeta: meta_v1.ObjectMeta{Name: storageClassName}, Provisioner: "csi-powerstore.dellemc.com"}
+			_, _ = clientset.StorageV1().StorageClasses().Create(context.TODO(), sc, meta_v1.CreateOptions{})
+			for name, className := range map[string]string{"pvc1": storageClassName, "pvc2": "other"} {
+				pvc := &v1.PersistentVolumeClaim{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "app"},
+					Spec: v1.PersistentVolumeClaimSpec{StorageClassName: &className}}
+				_, _ = clientset.CoreV1().PersistentVolumeClaims("app").Create(context.TODO(), pvc, meta_v1.CreateOptions{})
+			}
+			pv := &v1.PersistentVolume{ObjectMeta: meta_v1.ObjectMeta{Name: "pv1"},
+				Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{CSI: &v1.CSIPersistentVolumeSource{Driver: "csi-powerstore.dellemc.com"}}}}
+			_, _ = clientset.CoreV1().PersistentVolumes().Create(context.TODO(), pv, meta_v1.CreateOptions{})
+			va := &storagev1.VolumeAttachment{ObjectMeta: meta_v1.ObjectMeta{Name: "va1"}, Spec: storagev1.VolumeAttachmentSpec{Attacher: "csi-powerstore.dellemc.com"}}
+			_, _ = clientset.StorageV1().VolumeAttachments().Create(context.TODO(), va, meta_v1.CreateOptions{})
+
+			CreateEvent(client, "csi-powerstore", "pod-event", v1.ObjectReference{Kind: "Pod", Namespace: "csi-powerstore", Name: "node-1"}, now)
+			CreateEvent(client, "csi-powerstore", "old-pod-event", v1.ObjectReference{Kind: "Pod", Namespace: "csi-powerstore", Name: "node-1"}, now.Add(-5*time.Hour))
+			CreateEvent(client, "app", "pvc-event", v1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "app", Name: "pvc1"}, now.Add(-time.Hour))
+			CreateEvent(client, "app", "other-pvc-event", v1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "app", Name: "pvc2"}, now)
+			CreateEvent(client, "default", "pv-event", v1.ObjectReference{Kind: "PersistentVolume", Name: "pv1"}, now.Add(-50*time.Minute))
+			CreateEvent(client, "default", "attach-event", v1.ObjectReference{Kind: "VolumeAttachment", Name: "va1"}, now.Add(-40*time.Minute))
+			CreateEvent(client, "app", "app-pod-event", v1.ObjectReference{Kind: "Pod", Namespace: "app", Name: "app-1"}, now)
+
+			st := StorageNameSpaceStruct{namespaceName: "csi-powerstore", provisioner: "csi-powerstore.dellemc.com"}
+			events, err := st.getEvents(test.dateRange)
+			if err != nil {
+				t.Errorf("getting events failed with error: %s", err)
+				return
+			}
+			var got []string
+			for _, event := range events {
+				got = append(got, event.Name)
+			}
+			if diff := cmp.Diff(got, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+
+			namespaceDirectoryName := createDirectory("events-logs")
+			defer os.RemoveAll(namespaceDirectoryName)
+			st.collectEvents(namespaceDirectoryName, test.dateRange)
+			for _, file := range []string{"events.txt", "events.json"} {
+				data, err := ioutil.ReadFile(namespaceDirectoryName + "/events/" + file)
+				if err != nil || !strings.Contains(string(data), "pod-event") {
+					t.Errorf("events not written to %s: %v", file, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	utils "csm-logcollector/utils"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// helmReleaseSecretType is the type of the secrets in which Helm stores the releases
+const helmReleaseSecretType = "helm.sh/release.v1"
+
+// helmRelease holds the fields of a Helm release used by the log collector
+type helmRelease struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Info    struct {
+		Status        string `json:"status"`
+		FirstDeployed string `json:"first_deployed"`
+		LastDeployed  string `json:"last_deployed"`
+		Description   string `json:"description"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+	// Config holds the values supplied by the user
+	Config map[string]interface{} `json:"config"`
+}
+
+// collectHelmReleases writes the user supplied values of the latest revision of the Helm releases
+// of the driver namespace, with their sensitive values masked, along with the history of the releases
+func (s StorageNameSpaceStruct) collectHelmReleases(namespaceDirectoryName string) {
+	fmt.Println("\n\nCollecting Helm releases..........")
+	releases, err := s.getHelmReleases()
+	if err != nil {
+		reportErrors("Collecting Helm releases of namespace "+s.namespaceName, err)
+		return
+	}
+	if len(releases) == 0 {
+		fmt.Printf("\tNo Helm release found in namespace %s\n", s.namespaceName)
+		return
+	}
+
+	var values bytes.Buffer
+	latest := latestHelmReleases(releases)
+	for i, release := range latest {
+		if i > 0 {
+			values.WriteString("---\n")
+		}
+		fmt.Printf("\tRelease: %s, revision: %d, chart: %s-%s\n", release.Name, release.Version, release.Chart.Metadata.Name, release.Chart.Metadata.Version)
+		fmt.Fprintf(&values, "# release: %s\n# revision: %d\n# chart: %s-%s\n", release.Name, release.Version,
+			release.Chart.Metadata.Name, release.Chart.Metadata.Version)
+		data, err := yaml.Marshal(utils.MaskSensitiveValues(release.Config))
+		if err != nil {
+			reportErrors("Collecting Helm releases of namespace "+s.namespaceName, err)
+			return
+		}
+		values.Write(data)
+	}
+
+	helmDirectoryName := createDirectory(namespaceDirectoryName + "/helm")
+	sensitiveContentList := getSensitiveContent(s.namespaceName)
+	if err := writeSanitized(helmDirectoryName, "helm-values.yaml", values.Bytes(), sensitiveContentList); err != nil {
+		reportErrors("Writing Helm values", err)
+	}
+	if err := writeSanitized(helmDirectoryName, "helm-history.txt", formatHelmHistory(releases), sensitiveContentList); err != nil {
+		reportErrors("Writing Helm history", err)
+	}
+}
+
+// getHelmReleases decodes all the revisions of the Helm releases of the driver namespace
+func (s StorageNameSpaceStruct) getHelmReleases() ([]helmRelease, error) {
+	secrets, err := clientset.CoreV1().Secrets(s.namespaceName).List(context.TODO(), metav1.ListOptions{LabelSelector: "owner=helm"})
+	if err != nil {
+		return nil, fmt.Errorf("getting Helm release secrets failed with error: %s", err.Error())
+	}
+	var releases []helmRelease
+	for _, secret := range secrets.Items {
+		if secret.Type != helmReleaseSecretType {
+			continue
+		}
+		// a corrupted release secret does not prevent the collection of the other releases
+		release, err := decodeHelmRelease(secret.Data["release"])
+		if err != nil {
+			fmt.Printf("\tSkipping Helm release secret %s which cannot be decoded\n", secret.Name)
+			snsLog.Warnf("Decoding Helm release secret %s in namespace %s failed with error: %s", secret.Name, s.namespaceName, err.Error())
+			continue
+		}
+		releases = append(releases, release)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Name != releases[j].Name {
+			return releases[i].Name < releases[j].Name
+		}
+		return releases[i].Version < releases[j].Version
+	})
+	return releases, nil
+}
+
+// decodeHelmRelease decodes the release stored by Helm as base64 encoded, gzip compressed JSON
+func decodeHelmRelease(data []byte) (helmRelease, error) {
+	var release helmRelease
+	decoded, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return release, err
+	}
+	if bytes.HasPrefix(decoded, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return release, err
+		}
+		decoded, err = ioutil.ReadAll(r)
+		if closeErr := r.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return release, err
+		}
+	}
+	err = json.Unmarshal(decoded, &release)
+	return release, err
+}
+
+// latestHelmReleases returns the latest revision of every release, the releases are sorted by name and revision
+func latestHelmReleases(releases []helmRelease) []helmRelease {
+	var latest []helmRelease
+	for i, release := range releases {
+		if i == len(releases)-1 || releases[i+1].Name != release.Name {
+			latest = append(latest, release)
+		}
+	}
+	return latest
+}
+
+// formatHelmHistory returns the revisions of the releases as a table similar to helm history
+func formatHelmHistory(releases []helmRelease) []byte {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "RELEASE\tREVISION\tUPDATED\tSTATUS\tCHART\tAPP VERSION\tDESCRIPTION")
+	for _, release := range releases {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s-%s\t%s\t%s\n", release.Name, release.Version, release.Info.LastDeployed, release.Info.Status,
+			release.Chart.Metadata.Name, release.Chart.Metadata.Version, release.Chart.Metadata.AppVersion, strings.TrimSpace(release.Info.Description))
+	}
+	w.Flush()
+	return buf.Bytes()
+}
@@ -0,0 +1,87 @@
+package csm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func CreateHelmRelease(clientset kubernetes.Interface, namespace string, name string, revision int, status string, values map[string]interface{}) {
+	release := map[string]interface{}{
+		"name":    name,
+		"version": revision,
+		"info":    map[string]interface{}{"status": status, "last_deployed": "2022-03-01T10:00:00Z", "description": "Install complete"},
+		"chart":   map[string]interface{}{"metadata": map[string]interface{}{"name": "csi-powerstore", "version": "2.2.0", "appVersion": "2.2.0"}},
+		"config":  values,
+	}
+	data, _ := json.Marshal(release)
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, _ = w.Write(data)
+	_ = w.Close()
+	secret := &v1.Secret{ObjectMeta: meta_v1.ObjectMeta{Name: "sh.helm.release.v1." + name + ".v" + strconv.Itoa(revision), Namespace: namespace,
+		Labels: map[string]string{"owner": "helm", "name": name}}, Type: helmReleaseSecretType,
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(compressed.Bytes()))}}
+	_, _ = clientset.CoreV1().Secrets(namespace).Create(context.TODO(), secret, meta_v1.CreateOptions{})
+}
+
+func TestCollectHelmReleases(t *testing.T) {
+	type tests = []struct {
+		description string
+		file        string
+		expected    []string
+		unexpected  []string
+	}
+	var collectHelmReleasesTests = tests{
+		{"values of the latest revision with the credentials masked", "helm-values.yaml",
+			[]string{"# release: powerstore\n# revision: 2\n# chart: csi-powerstore-2.2.0", "logLevel: info", "endpoint: '*********'"},
+			[]string{"logLevel: debug", "https://10.0.0.1"}},
+		{"history of the release", "helm-history.txt",
+			[]string{"powerstore  1         2022-03-01T10:00:00Z  superseded", "powerstore  2         2022-03-01T10:00:00Z  deployed"}, nil},
+	}
+	clientset = fake.NewSimpleClientset()
+	CreateHelmRelease(clientset, "powerstore", "powerstore", 1, "superseded", map[string]interface{}{"logLevel": "debug"})
+	CreateHelmRelease(clientset, "powerstore", "powerstore", 2, "deployed", map[string]interface{}{"logLevel": "info",
+		"arrays": []interface{}{map[string]interface{}{"endpoint": "https://10.0.0.1"}}})
+	// an undecodable release secret is skipped
+	corrupted := &v1.Secret{ObjectMeta: meta_v1.ObjectMeta{Name: "sh.helm.release.v1.powerstore.v3", Namespace: "powerstore",
+		Labels: map[string]string{"owner": "helm", "name": "powerstore"}}, Type: helmReleaseSecretType,
+		Data: map[string][]byte{"release": []byte("not a release")}}
+	_, _ = clientset.CoreV1().Secrets("powerstore").Create(context.TODO(), corrupted, meta_v1.CreateOptions{})
+
+	namespaceDirectoryName := createDirectory("helm-logs")
+	defer os.RemoveAll(namespaceDirectoryName)
+	s := StorageNameSpaceStruct{namespaceName: "powerstore"}
+	s.collectHelmReleases(namespaceDirectoryName)
+
+	for _, test := range collectHelmReleasesTests {
+		t.Run(test.description, func(t *testing.T) {
+			data, err := ioutil.ReadFile(namespaceDirectoryName + "/helm/" + test.file)
+			if err != nil {
+				t.Fatalf("file %s not collected: %s", test.file, err)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(string(data), expected) {
+					t.Errorf("%s does not contain %q:\n%s", test.file, expected, data)
+				}
+			}
+			for _, unexpected := range test.unexpected {
+				if strings.Contains(string(data), unexpected) {
+					t.Errorf("%s contains %q:\n%s", test.file, unexpected, data)
+				}
+			}
+		})
+	}
+}
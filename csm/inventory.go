@@ -0,0 +1,368 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"context"
+	utils "csm-logcollector/utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	describe "k8s.io/kubectl/pkg/describe"
+	"sigs.k8s.io/yaml"
+)
+
+// inventoryObjects holds the objects of a kind collected into the inventory
+type inventoryObjects struct {
+	kind    string
+	version schema.GroupVersion
+	objects []runtime.Object
+	// describer is nil for the kinds which kubectl does not describe, e.g. CSIDriver
+	describer describe.ResourceDescriber
+}
+
+// getProvisioner returns the name of the CSIDriver object of the driver installed in the namespace
+func getProvisioner(namespace string, driver Driver) string {
+	csiDrivers, err := clientset.StorageV1().CSIDrivers().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		snsLog.Errorf("Getting CSI drivers failed with error: %s", err.Error())
+		return ""
+	}
+	var candidates []string
+	for _, csiDriver := range csiDrivers.Items {
+		if driver.MatchesName(csiDriver.Name) {
+			candidates = append(candidates, csiDriver.Name)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	leases, err := clientset.CoordinationV1().Leases(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		snsLog.Errorf("Getting leases in namespace %s failed with error: %s", namespace, err.Error())
+		return ""
+	}
+	for _, candidate := range candidates {
+		for _, lease := range leases.Items {
+			if leaseMatchesProvisioner(lease.Name, candidate) {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// leaseMatchesProvisioner verifies if the lease is named after the provisioner, e.g. driver-csi-powermax-dellemc-com
+func leaseMatchesProvisioner(leaseName string, provisioner string) bool {
+	return strings.Contains(leaseName, strings.ReplaceAll(provisioner, ".", "-"))
+}
+
+// collectInventory dumps the CSI related objects of the driver as YAML and describes them
+// under the inventory directory, the cluster level objects are filtered by the driver provisioner
+func (s StorageNameSpaceStruct) collectInventory(namespaceDirectoryName string) {
+	fmt.Println("\n\nCollecting CSI object inventory..........")
+	inventoryDirectoryName := createDirectory(namespaceDirectoryName + "/inventory")
+	if s.provisioner == "" {
+		fmt.Printf("\tProvisioner of the driver in namespace %s is not found, cluster level CSI objects will not be collected\n", s.namespaceName)
+		snsLog.Warnf("Provisioner of the driver in namespace %s is not found", s.namespaceName)
+	} else {
+		fmt.Printf("\tProvisioner: \t%s\n", s.provisioner)
+	}
+
+	listers := []func() ([]inventoryObjects, error){
+		s.listCSIDrivers, s.listCSINodes, s.listStorageClasses, s.listVolumeAttachments, s.listPersistentVolumes,
+		s.listDaemonSets, s.listDeployments, s.listConfigMaps, s.listServiceAccounts, s.listRBAC,
+	}
+	pool := newWorkerPool()
+	for _, lister := range listers {
+		lister := lister
+		pool.Go(func() error {
+			inventories, err := lister()
+			if err != nil {
+				return err
+			}
+			for _, inventory := range inventories {
+				if err := s.writeInventory(inventoryDirectoryName, inventory); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	reportErrors("Collecting inventory in namespace "+s.namespaceName, pool.Wait())
+}
+
+// writeInventory writes every object as YAML, with the values of the sensitive keys masked and sanitized
+// against the driver secrets, along with its describe
+func (s StorageNameSpaceStruct) writeInventory(inventoryDirectoryName string, inventory inventoryObjects) error {
+	if len(inventory.objects) == 0 {
+		return nil
+	}
+	kindDirectoryName := createDirectory(inventoryDirectoryName + "/" + strings.ToLower(inventory.kind))
+	for _, object := range inventory.objects {
+		accessor, err := meta.Accessor(object)
+		if err != nil {
+			return err
+		}
+		accessor.SetManagedFields(nil)
+		object.GetObjectKind().SetGroupVersionKind(inventory.version.WithKind(inventory.kind))
+		data, err := marshalMasked(object)
+		if err != nil {
+			return fmt.Errorf("converting %s %s to YAML failed with error: %s", inventory.kind, accessor.GetName(), err.Error())
+		}
+		if err := writeSanitized(kindDirectoryName, accessor.GetName()+".yaml", data, getSensitiveContent(s.namespaceName)); err != nil {
+			return err
+		}
+
+		if inventory.describer != nil {
+			details, err := inventory.describer.Describe(accessor.GetNamespace(), accessor.GetName(), describe.DescriberSettings{ShowEvents: true})
+			if err != nil {
+				return fmt.Errorf("describing %s %s failed with error: %s", inventory.kind, accessor.GetName(), err.Error())
+			}
+			if err := writeSanitized(kindDirectoryName, accessor.GetName()+"-describe.txt", []byte(details), getSensitiveContent(s.namespaceName)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// marshalMasked returns the object as YAML with the values of the sensitive keys masked, the object
+// is converted to its unmarshalled content first so that the masking does not modify it
+func marshalMasked(object runtime.Object) ([]byte, error) {
+	data, err := yaml.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	var content interface{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(utils.MaskSensitiveValues(content))
+}
+
+// writeSanitized writes the content to the file with the sensitive content masked
+func writeSanitized(repoName string, filename string, content []byte, sensitiveContentList []string) error {
+	f, err := os.Create(filepath.Clean(repoName + "/" + filename))
+	if err != nil {
+		return fmt.Errorf("creating file %s failed with error: %s", filename, err.Error())
+	}
+	w := utils.NewSanitizingWriter(f, sensitiveContentList)
+	_, err = w.Write(content)
+	if err == nil {
+		err = w.Close()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (s StorageNameSpaceStruct) listCSIDrivers() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "CSIDriver", version: storagev1.SchemeGroupVersion}
+	if s.provisioner == "" {
+		return []inventoryObjects{inventory}, nil
+	}
+	csiDriver, err := clientset.StorageV1().CSIDrivers().Get(context.TODO(), s.provisioner, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting CSI driver %s failed with error: %s", s.provisioner, err.Error())
+	}
+	inventory.objects = append(inventory.objects, csiDriver)
+	return []inventoryObjects{inventory}, nil
+}
+
+func (s StorageNameSpaceStruct) listCSINodes() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "CSINode", version: storagev1.SchemeGroupVersion, describer: &describe.CSINodeDescriber{Interface: clientset}}
+	if s.provisioner == "" {
+		return []inventoryObjects{inventory}, nil
+	}
+	csiNodes, err := clientset.StorageV1().CSINodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting CSI nodes failed with error: %s", err.Error())
+	}
+	for i := range csiNodes.Items {
+		for _, driver := range csiNodes.Items[i].Spec.Drivers {
+			if driver.Name == s.provisioner {
+				inventory.objects = append(inventory.objects, &csiNodes.Items[i])
+				break
+			}
+		}
+	}
+	return []inventoryObjects{inventory}, nil
+}
+
+func (s StorageNameSpaceStruct) listStorageClasses() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "StorageClass", version: storagev1.SchemeGroupVersion, describer: &describe.StorageClassDescriber{Interface: clientset}}
+	if s.provisioner == "" {
+		return []inventoryObjects{inventory}, nil
+	}
+	storageClasses, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting storage classes failed with error: %s", err.Error())
+	}
+	for i := range storageClasses.Items {
+		if storageClasses.Items[i].Provisioner == s.provisioner {
+			inventory.objects = append(inventory.objects, &storageClasses.Items[i])
+		}
+	}
+	return []inventoryObjects{inventory}, nil
+}
+
+func (s StorageNameSpaceStruct) listVolumeAttachments() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "VolumeAttachment", version: storagev1.SchemeGroupVersion}
+	if s.provisioner == "" {
+		return []inventoryObjects{inventory}, nil
+	}
+	volumeAttachments, err := clientset.StorageV1().VolumeAttachments().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting volume attachments failed with error: %s", err.Error())
+	}
+	for i := range volumeAttachments.Items {
+		if volumeAttachments.Items[i].Spec.Attacher == s.provisioner {
+			inventory.objects = append(inventory.objects, &volumeAttachments.Items[i])
+		}
+	}
+	return []inventoryObjects{inventory}, nil
+}
+
+func (s StorageNameSpaceStruct) listPersistentVolumes() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "PersistentVolume", version: corev1.SchemeGroupVersion, describer: &describe.PersistentVolumeDescriber{Interface: clientset}}
+	if s.provisioner == "" {
+		return []inventoryObjects{inventory}, nil
+	}
+	persistentVolumes, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting persistent volumes failed with error: %s", err.Error())
+	}
+	for i := range persistentVolumes.Items {
+		csi := persistentVolumes.Items[i].Spec.CSI
+		if csi != nil && csi.Driver == s.provisioner {
+			inventory.objects = append(inventory.objects, &persistentVolumes.Items[i])
+		}
+	}
+	return []inventoryObjects{inventory}, nil
+}
+
+func (s StorageNameSpaceStruct) listDaemonSets() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "DaemonSet", version: appsv1.SchemeGroupVersion, describer: &describe.DaemonSetDescriber{Interface: clientset}}
+	daemonSets, err := clientset.AppsV1().DaemonSets(s.namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting daemonsets in namespace %s failed with error: %s", s.namespaceName, err.Error())
+	}
+	for i := range daemonSets.Items {
+		inventory.objects = append(inventory.objects, &daemonSets.Items[i])
+	}
+	return []inventoryObjects{inventory}, nil
+}
+
+// listDeployments lists the deployments of the namespace, kubectl does not expose a deployment describer without the REST config
+func (s StorageNameSpaceStruct) listDeployments() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "Deployment", version: appsv1.SchemeGroupVersion}
+	deployments, err := clientset.AppsV1().Deployments(s.namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting deployments in namespace %s failed with error: %s", s.namespaceName, err.Error())
+	}
+	for i := range deployments.Items {
+		inventory.objects = append(inventory.objects, &deployments.Items[i])
+	}
+	return []inventoryObjects{inventory}, nil
+}
+
+func (s StorageNameSpaceStruct) listConfigMaps() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "ConfigMap", version: corev1.SchemeGroupVersion, describer: &describe.ConfigMapDescriber{Interface: clientset}}
+	configMaps, err := clientset.CoreV1().ConfigMaps(s.namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting configmaps in namespace %s failed with error: %s", s.namespaceName, err.Error())
+	}
+	for i := range configMaps.Items {
+		inventory.objects = append(inventory.objects, &configMaps.Items[i])
+	}
+	return []inventoryObjects{inventory}, nil
+}
+
+func (s StorageNameSpaceStruct) listServiceAccounts() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "ServiceAccount", version: corev1.SchemeGroupVersion, describer: &describe.ServiceAccountDescriber{Interface: clientset}}
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts(s.namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting service accounts in namespace %s failed with error: %s", s.namespaceName, err.Error())
+	}
+	for i := range serviceAccounts.Items {
+		inventory.objects = append(inventory.objects, &serviceAccounts.Items[i])
+	}
+	return []inventoryObjects{inventory}, nil
+}
+
+// listRBAC lists the roles and bindings of the namespace along with the cluster roles and
+// cluster role bindings granted to the service accounts of the namespace
+func (s StorageNameSpaceStruct) listRBAC() ([]inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "Role", version: rbacv1.SchemeGroupVersion, describer: &describe.RoleDescriber{Interface: clientset}}
+	roles, err := clientset.RbacV1().Roles(s.namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting roles in namespace %s failed with error: %s", s.namespaceName, err.Error())
+	}
+	for i := range roles.Items {
+		inventory.objects = append(inventory.objects, &roles.Items[i])
+	}
+
+	clusterRoleNames := make(map[string]bool)
+	roleBindings := inventoryObjects{kind: "RoleBinding", version: rbacv1.SchemeGroupVersion, describer: &describe.RoleBindingDescriber{Interface: clientset}}
+	roleBindingList, err := clientset.RbacV1().RoleBindings(s.namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting role bindings in namespace %s failed with error: %s", s.namespaceName, err.Error())
+	}
+	for i := range roleBindingList.Items {
+		roleBindings.objects = append(roleBindings.objects, &roleBindingList.Items[i])
+		if roleBindingList.Items[i].RoleRef.Kind == "ClusterRole" {
+			clusterRoleNames[roleBindingList.Items[i].RoleRef.Name] = true
+		}
+	}
+
+	clusterRoleBindings := inventoryObjects{kind: "ClusterRoleBinding", version: rbacv1.SchemeGroupVersion, describer: &describe.ClusterRoleBindingDescriber{Interface: clientset}}
+	clusterRoleBindingList, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting cluster role bindings failed with error: %s", err.Error())
+	}
+	for i := range clusterRoleBindingList.Items {
+		for _, subject := range clusterRoleBindingList.Items[i].Subjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == s.namespaceName {
+				clusterRoleBindings.objects = append(clusterRoleBindings.objects, &clusterRoleBindingList.Items[i])
+				clusterRoleNames[clusterRoleBindingList.Items[i].RoleRef.Name] = true
+				break
+			}
+		}
+	}
+
+	clusterRoles := inventoryObjects{kind: "ClusterRole", version: rbacv1.SchemeGroupVersion, describer: &describe.ClusterRoleDescriber{Interface: clientset}}
+	for name := range clusterRoleNames {
+		clusterRole, err := clientset.RbacV1().ClusterRoles().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			snsLog.Warnf("Getting cluster role %s failed with error: %s", name, err.Error())
+			continue
+		}
+		clusterRoles.objects = append(clusterRoles.objects, clusterRole)
+	}
+
+	return []inventoryObjects{inventory, roleBindings, clusterRoleBindings, clusterRoles}, nil
+}
@@ -0,0 +1,147 @@
+package csm
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetProvisioner(t *testing.T) {
+	type tests = []struct {
+		description string
+		csiDrivers  []string
+		leases      []string
+		expected    string
+	}
+	var getProvisionerTests = tests{
+		{"single CSI driver", []string{"csi-powerstore.dellemc.com"}, nil, "csi-powerstore.dellemc.com"},
+		{"CSI driver matched by lease", []string{"csi-powerstore.dellemc.com", "csi-powerstore-2.dellemc.com"},
+			[]string{"external-attacher-leader-csi-powerstore-2-dellemc-com"}, "csi-powerstore-2.dellemc.com"},
+		{"no CSI driver", nil, nil, ""},
+	}
+	driver, _ := LookupDriver("powerstore")
+	for _, test := range getProvisionerTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			for _, name := range test.csiDrivers {
+				_ = CreateCSIDriver(clientset, name)
+			}
+			for _, name := range test.leases {
+				_ = CreateLease(clientset, name, "csi-powerstore", "controller-0")
+			}
+			if diff := cmp.Diff(getProvisioner("csi-powerstore", driver), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestCollectInventory(t *testing.T) {
+	type tests = []struct {
+		description      string
+		expectedFiles    []string
+		unexpectedFiles  []string
+		sanitizedFile    string
+		sensitiveContent string
+	}
+	var collectInventoryTests = tests{
+		{"inventory filtered by provisioner",
+			[]string{"csidriver/csi-powerstore.dellemc.com.yaml", "storageclass/powerstore.yaml", "storageclass/powerstore-describe.txt",
+				"persistentvolume/pv1.yaml", "daemonset/powerstore-node.yaml", "configmap/powerstore-config-params.yaml",
+				"clusterrolebinding/powerstore-node.yaml", "clusterrole/powerstore-node.yaml"},
+			[]string{"storageclass/unity.yaml", "persistentvolume/pv2.yaml"},
+			"configmap/powerstore-config-params.yaml", "Password123"},
+	}
+	for _, test := range collectInventoryTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			_ = CreateCSIDriver(clientset, "csi-powerstore.dellemc.com")
+			_ = CreateDaemonSet(clientset, "csi-powerstore", "powerstore-node", "dellemc/csi-powerstore:v2.1.0")
+			for name, provisioner := range map[string]string{"powerstore": "csi-powerstore.dellemc.com", "unity": "csi-unity.dellemc.com"} {
+				sc := &storagev1.StorageClass{ObjectMeta: meta_v1.ObjectMeta{Name: name}, Provisioner: provisioner}
+				_, _ = clientset.StorageV1().StorageClasses().Create(context.TODO(), sc, meta_v1.CreateOptions{})
+			}
+			for name, provisioner := range map[string]string{"pv1": "csi-powerstore.dellemc.com", "pv2": "csi-unity.dellemc.com"} {
+				pv := &v1.PersistentVolume{ObjectMeta: meta_v1.ObjectMeta{Name: name},
+					Spec: v1.PersistentVolumeSpec{PersistentVolumeSource: v1.PersistentVolumeSource{CSI: &v1.CSIPersistentVolumeSource{Driver: provisioner}}}}
+				_, _ = clientset.CoreV1().PersistentVolumes().Create(context.TODO(), pv, meta_v1.CreateOptions{})
+			}
+			cm := &v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: "powerstore-config-params", Namespace: "csi-powerstore"},
+				Data: map[string]string{"config": "password: " + test.sensitiveContent}}
+			_, _ = clientset.CoreV1().ConfigMaps("csi-powerstore").Create(context.TODO(), cm, meta_v1.CreateOptions{})
+			crb := &rbacv1.ClusterRoleBinding{ObjectMeta: meta_v1.ObjectMeta{Name: "powerstore-node"},
+				Subjects: []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "powerstore-node", Namespace: "csi-powerstore"}},
+				RoleRef:  rbacv1.RoleRef{Kind: "ClusterRole", Name: "powerstore-node"}}
+			_, _ = clientset.RbacV1().ClusterRoleBindings().Create(context.TODO(), crb, meta_v1.CreateOptions{})
+			cr := &rbacv1.ClusterRole{ObjectMeta: meta_v1.ObjectMeta{Name: "powerstore-node"}}
+			_, _ = clientset.RbacV1().ClusterRoles().Create(context.TODO(), cr, meta_v1.CreateOptions{})
+			setSensitiveContent("csi-powerstore", []string{test.sensitiveContent})
+			defer setSensitiveContent("csi-powerstore", nil)
+
+			namespaceDirectoryName := createDirectory("inventory-logs")
+			defer os.RemoveAll(namespaceDirectoryName)
+			driver, _ := LookupDriver("powerstore")
+			st := StorageNameSpaceStruct{namespaceName: "csi-powerstore", driver: driver, provisioner: getProvisioner("csi-powerstore", driver)}
+			st.collectInventory(namespaceDirectoryName)
+
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(namespaceDirectoryName + "/inventory/" + file); err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+				}
+			}
+			for _, file := range test.unexpectedFiles {
+				if _, err := os.Stat(namespaceDirectoryName + "/inventory/" + file); err == nil {
+					t.Errorf("file %s of another provisioner collected", file)
+				}
+			}
+			data, _ := ioutil.ReadFile(namespaceDirectoryName + "/inventory/" + test.sanitizedFile)
+			if strings.Contains(string(data), test.sensitiveContent) || !strings.Contains(string(data), "kind: ConfigMap") {
+				t.Errorf("file %s not sanitized: %s", test.sanitizedFile, data)
+			}
+		})
+	}
+}
+
+func TestMarshalMasked(t *testing.T) {
+	type tests = []struct {
+		description string
+		object      runtime.Object
+		expected    string
+	}
+	var marshalMaskedTests = tests{
+		{"sensitive keys of a config map masked",
+			&v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: "config"}, Data: map[string]string{"password": "Password123", "logLevel": "debug"}},
+			"data:\n  logLevel: debug\n  password: '*********'\nmetadata:\n  creationTimestamp: null\n  name: config\n"},
+		{"nested sensitive keys of a custom resource masked",
+			&unstructured.Unstructured{Object: map[string]interface{}{"kind": "ContainerStorageModule",
+				"spec": map[string]interface{}{"driver": map[string]interface{}{"endpoint": "10.0.0.1", "csiDriverType": "powerstore"}}}},
+			"kind: ContainerStorageModule\nspec:\n  driver:\n    csiDriverType: powerstore\n    endpoint: '*********'\n"},
+	}
+	for _, test := range marshalMaskedTests {
+		t.Run(test.description, func(t *testing.T) {
+			original := test.object.DeepCopyObject()
+			data, err := marshalMasked(test.object)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if diff := cmp.Diff(string(data), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+			if diff := cmp.Diff(test.object, original); diff != "" {
+				t.Errorf("object modified by the masking: %s", diff)
+			}
+		})
+	}
+}
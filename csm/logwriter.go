@@ -0,0 +1,125 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"compress/gzip"
+	utils "csm-logcollector/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// LogFilters configures the filters the container logs are streamed through to the disk
+type LogFilters struct {
+	// Compress writes the container logs gzip compressed, it implies Sanitize
+	Compress bool
+	// Sanitize masks the sensitive content of the driver secrets while the logs are streamed
+	Sanitize bool
+}
+
+var logFilters LogFilters
+
+// sensitiveContent holds the sensitive strings of the driver namespaces for the sanitization filter
+var sensitiveContent = struct {
+	sync.RWMutex
+	namespaces map[string][]string
+}{namespaces: make(map[string][]string)}
+
+// SetLogFilters sets the filters used for streaming the container logs
+func SetLogFilters(filters LogFilters) {
+	if filters.Compress {
+		filters.Sanitize = true
+	}
+	logFilters = filters
+}
+
+func setSensitiveContent(namespace string, sensitiveContentList []string) {
+	sensitiveContent.Lock()
+	defer sensitiveContent.Unlock()
+	sensitiveContent.namespaces[namespace] = sensitiveContentList
+}
+
+func getSensitiveContent(namespace string) []string {
+	sensitiveContent.RLock()
+	defer sensitiveContent.RUnlock()
+	return sensitiveContent.namespaces[namespace]
+}
+
+// getAllSensitiveContent returns the sensitive strings of all the namespaces, the logs of a namespace
+// may hold the secrets of another one, e.g. the modules logging the requests of every driver
+func getAllSensitiveContent() []string {
+	sensitiveContent.RLock()
+	defer sensitiveContent.RUnlock()
+	var namespaces []string
+	for namespace := range sensitiveContent.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	var sensitiveContentList []string
+	seen := make(map[string]bool)
+	for _, namespace := range namespaces {
+		for _, content := range sensitiveContent.namespaces[namespace] {
+			if !seen[content] {
+				seen[content] = true
+				sensitiveContentList = append(sensitiveContentList, content)
+			}
+		}
+	}
+	return sensitiveContentList
+}
+
+// logFile is the output file of a container log along with its filters,
+// closing it flushes and closes the filters before the file
+type logFile struct {
+	io.Writer
+	closers []io.Closer
+}
+
+// createLogFile creates the log file of a container through the configured filters, the logs are masked
+// with the sensitive content of all the namespaces as the compressed logs are not sanitized afterwards
+func createLogFile(repoName string, filename string) (*logFile, error) {
+	if logFilters.Compress {
+		filename += ".gz"
+	}
+	f, err := os.Create(filepath.Clean(repoName + "/" + filename))
+	if err != nil {
+		return nil, err
+	}
+	l := &logFile{Writer: f, closers: []io.Closer{f}}
+	if logFilters.Compress {
+		gz := gzip.NewWriter(l.Writer)
+		l.Writer = gz
+		l.closers = append([]io.Closer{gz}, l.closers...)
+	}
+	if logFilters.Sanitize {
+		sw := utils.NewSanitizingWriter(l.Writer, getAllSensitiveContent())
+		l.Writer = sw
+		l.closers = append([]io.Closer{sw}, l.closers...)
+	}
+	return l, nil
+}
+
+// Close closes the filters and the file, the first error is returned
+func (l *logFile) Close() error {
+	var err error
+	for _, closer := range l.closers {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
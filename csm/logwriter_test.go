@@ -0,0 +1,67 @@
+package csm
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCaptureContainerLogs(t *testing.T) {
+	type tests = []struct {
+		description        string
+		filters            LogFilters
+		sensitiveNamespace string
+		sensitive          []string
+		expectedFile       string
+		expected           string
+	}
+	var captureContainerLogsTests = tests{
+		{"plain log", LogFilters{}, "csi-test", []string{"fake"}, "pod1-driver.txt", "fake logs"},
+		{"sanitized log", LogFilters{Sanitize: true}, "csi-test", []string{"fake"}, "pod1-driver.txt", "********* logs"},
+		{"compressed log", LogFilters{Compress: true}, "csi-test", []string{"fake"}, "pod1-driver.txt.gz", "********* logs"},
+		{"compressed log with secrets of another namespace", LogFilters{Compress: true}, "csi-other", []string{"fake"}, "pod1-driver.txt.gz", "********* logs"},
+	}
+	defer SetLogFilters(LogFilters{})
+
+	for _, test := range captureContainerLogsTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			SetLogFilters(test.filters)
+			setSensitiveContent(test.sensitiveNamespace, test.sensitive)
+			defer setSensitiveContent(test.sensitiveNamespace, nil)
+			pod := CreatePod(clientset, "csi-test", "pod1", "driver")
+			containerDirectoryName := createDirectory("container-logs")
+			defer os.RemoveAll(containerDirectoryName)
+
+			s := StorageNameSpaceStruct{namespaceName: "csi-test"}
+			if err := s.captureContainerLogs(pod, "driver", nil, containerDirectoryName, false); err != nil {
+				t.Errorf("capturing container logs failed with error: %s", err)
+				return
+			}
+			f, err := os.Open(containerDirectoryName + "/" + test.expectedFile)
+			if err != nil {
+				t.Errorf("log file not created: %s", err)
+				return
+			}
+			defer f.Close()
+			var data []byte
+			if test.filters.Compress {
+				gz, err := gzip.NewReader(f)
+				if err != nil {
+					t.Errorf("log file not compressed: %s", err)
+					return
+				}
+				data, _ = ioutil.ReadAll(gz)
+			} else {
+				data, _ = ioutil.ReadAll(f)
+			}
+			if diff := cmp.Diff(string(data), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
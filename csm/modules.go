@@ -0,0 +1,291 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"context"
+	utils "csm-logcollector/utils"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	describe "k8s.io/kubectl/pkg/describe"
+)
+
+// Module holds the details which a CSM module registers with the log collector
+type Module struct {
+	// Name is the module name accepted on the command line, e.g. authorization
+	Name string
+	// DisplayName is the module name shown to the user
+	DisplayName string
+	// Detect returns the namespaces in which the module is installed
+	Detect func() []string
+	// Collect collects the module details of the namespace into the module directory
+	Collect func(namespace string, moduleDirectoryName string, dateRange *metav1.Time)
+	// CollectCluster optionally collects the module details which are not bound to a namespace of the module,
+	// once per cluster into the module directory
+	CollectCluster func(moduleDirectoryName string, dateRange *metav1.Time)
+	// CollectRemote optionally collects the module from the remote clusters into the bundle directory
+	CollectRemote func(bundleDirectoryName string, dateRange *metav1.Time)
+}
+
+var moduleRegistry = make(map[string]Module)
+
+// enabledModules holds the names of the modules to be collected, nil collects all the modules
+var enabledModules []string
+
+// RegisterModule registers a CSM module with the log collector
+func RegisterModule(module Module) {
+	moduleRegistry[module.Name] = module
+}
+
+// GetModules returns the registered CSM modules sorted by name
+func GetModules() []Module {
+	var modules []Module
+	for _, module := range moduleRegistry {
+		modules = append(modules, module)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Name < modules[j].Name
+	})
+	return modules
+}
+
+// LookupModule returns the registered CSM module for the given name
+func LookupModule(name string) (Module, bool) {
+	module, ok := moduleRegistry[strings.ToLower(strings.TrimSpace(name))]
+	return module, ok
+}
+
+// SetModules sets the CSM modules to be collected along with the drivers
+func SetModules(names []string) {
+	enabledModules = names
+}
+
+// moduleEnabled verifies if the module is to be collected
+func moduleEnabled(name string) bool {
+	if enabledModules == nil {
+		return true
+	}
+	for _, enabled := range enabledModules {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// servedResource returns the custom resource at the version served by the cluster, the preferred version
+// of the group first, the given version is returned when the resource is not found through discovery
+func servedResource(resource schema.GroupVersionResource) schema.GroupVersionResource {
+	groups, err := clientset.Discovery().ServerGroups()
+	if err != nil {
+		snsLog.Infof("Discovering the version of %s failed with error: %s", resource.GroupResource().String(), err.Error())
+		return resource
+	}
+	for _, group := range groups.Groups {
+		if group.Name != resource.Group {
+			continue
+		}
+		checked := make(map[string]bool)
+		for _, version := range append([]metav1.GroupVersionForDiscovery{group.PreferredVersion}, group.Versions...) {
+			if checked[version.Version] {
+				continue
+			}
+			checked[version.Version] = true
+			resources, err := clientset.Discovery().ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				continue
+			}
+			for _, apiResource := range resources.APIResources {
+				if apiResource.Name == resource.Resource {
+					return resource.GroupResource().WithVersion(version.Version)
+				}
+			}
+		}
+	}
+	snsLog.Infof("Resource %s is not served by the cluster, version %s is used", resource.GroupResource().String(), resource.Version)
+	return resource
+}
+
+// collectModules collects the installed CSM modules under the modules directory of the bundle
+func collectModules(bundleDirectoryName string, dateRange *metav1.Time) {
+	for _, module := range GetModules() {
+		if !moduleEnabled(module.Name) {
+			continue
+		}
+		collectModuleNamespaces(module, bundleDirectoryName+"/modules/"+module.Name, dateRange)
+		if module.CollectRemote != nil {
+			module.CollectRemote(bundleDirectoryName, dateRange)
+		}
+	}
+}
+
+// collectModuleNamespaces collects the module from every namespace it is installed in
+// into a subdirectory of the module directory named after the namespace
+func collectModuleNamespaces(module Module, moduleDirectoryName string, dateRange *metav1.Time) {
+	namespaces := module.Detect()
+	if len(namespaces) == 0 {
+		snsLog.Infof("CSM %s is not installed in the cluster", module.DisplayName)
+		return
+	}
+	fmt.Printf("\n\nCollecting CSM %s in namespaces %s..........\n", module.DisplayName, namespaces)
+	for _, namespace := range namespaces {
+		namespaceDirectoryName := createDirectory(moduleDirectoryName + "/" + namespace)
+		setSensitiveContent(namespace, utils.GetSensitiveContent(clientset, namespace))
+		module.Collect(namespace, namespaceDirectoryName, dateRange)
+		utils.SanitizeDirectory(namespaceDirectoryName, getSensitiveContent(namespace))
+	}
+	if module.CollectCluster != nil {
+		module.CollectCluster(moduleDirectoryName, dateRange)
+	}
+}
+
+// detectDeployments returns the namespaces having any of the given deployments
+func detectDeployments(names ...string) []string {
+	deployments, err := clientset.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		snsLog.Errorf("Getting deployments failed with error: %s", err.Error())
+		return nil
+	}
+	found := make(map[string]bool)
+	var namespaces []string
+	for _, deployment := range deployments.Items {
+		if containsAny(deployment.Name, names) && !found[deployment.Namespace] {
+			found[deployment.Namespace] = true
+			namespaces = append(namespaces, deployment.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// detectContainers returns the namespaces having pods with any of the given containers
+func detectContainers(names ...string) []string {
+	podList, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		snsLog.Errorf("Getting pods failed with error: %s", err.Error())
+		return nil
+	}
+	found := make(map[string]bool)
+	var namespaces []string
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if found[pod.Namespace] {
+			continue
+		}
+		for _, name := range names {
+			if hasContainer(pod, name) {
+				found[pod.Namespace] = true
+				namespaces = append(namespaces, pod.Namespace)
+				break
+			}
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// collectModulePods describes the pods of the module namespace whose name starts with any of the prefixes
+// and collects their logs, all the pods are collected when no prefix is given
+func collectModulePods(namespace string, moduleDirectoryName string, dateRange *metav1.Time, prefixes ...string) {
+	s := StorageNameSpaceStruct{namespaceName: namespace}
+	podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		reportErrors("Getting pods in namespace "+namespace, err)
+		return
+	}
+	pool := newWorkerPool()
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if len(prefixes) > 0 && !hasAnyPrefix(pod.Name, prefixes) {
+			continue
+		}
+		podDirectoryName := createDirectory(moduleDirectoryName + "/" + pod.Name)
+		pool.Go(func() error {
+			return s.describePod(pod.Name, describe.DescriberSettings{ShowEvents: true}, podDirectoryName)
+		})
+		if pod.Status.Phase == RunningPodState {
+			s.getRunningPods(s, pool, moduleDirectoryName, pod, dateRange, "true")
+		} else {
+			s.getNonRunningPods(s, pool, moduleDirectoryName, pod, dateRange)
+		}
+	}
+	reportErrors("Collecting pod logs in namespace "+namespace, pool.Wait())
+}
+
+// collectModuleConfig writes the ConfigMaps and Secrets of the module namespace whose name contains
+// any of the given names, the Secret values are redacted
+func collectModuleConfig(namespace string, moduleDirectoryName string, names ...string) {
+	s := StorageNameSpaceStruct{namespaceName: namespace}
+	configMaps := inventoryObjects{kind: "ConfigMap", version: corev1.SchemeGroupVersion, describer: &describe.ConfigMapDescriber{Interface: clientset}}
+	configMapList, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		reportErrors("Getting configmaps in namespace "+namespace, err)
+		return
+	}
+	for i := range configMapList.Items {
+		if containsAny(configMapList.Items[i].Name, names) {
+			configMaps.objects = append(configMaps.objects, &configMapList.Items[i])
+		}
+	}
+
+	secrets := inventoryObjects{kind: "Secret", version: corev1.SchemeGroupVersion, describer: &describe.SecretDescriber{Interface: clientset}}
+	secretList, err := clientset.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		reportErrors("Getting secrets in namespace "+namespace, err)
+		return
+	}
+	for i := range secretList.Items {
+		if containsAny(secretList.Items[i].Name, names) {
+			secrets.objects = append(secrets.objects, redactSecret(secretList.Items[i]))
+		}
+	}
+
+	configDirectoryName := createDirectory(moduleDirectoryName + "/config")
+	for _, inventory := range []inventoryObjects{configMaps, secrets} {
+		if err := s.writeInventory(configDirectoryName, inventory); err != nil {
+			reportErrors("Collecting config in namespace "+namespace, err)
+		}
+	}
+}
+
+// redactSecret returns a copy of the secret with its values redacted, the keys are kept
+func redactSecret(secret corev1.Secret) *corev1.Secret {
+	redacted := secret.DeepCopy()
+	redacted.Data = nil
+	redacted.StringData = make(map[string]string)
+	for key := range secret.Data {
+		redacted.StringData[key] = "<redacted>"
+	}
+	for key := range secret.StringData {
+		redacted.StringData[key] = "<redacted>"
+	}
+	// the last applied configuration holds the secret values
+	delete(redacted.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	return redacted
+}
+
+// hasAnyPrefix verifies if the string starts with any of the prefixes
+func hasAnyPrefix(str string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(str, prefix) {
+			return true
+		}
+	}
+	return false
+}
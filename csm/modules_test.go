@@ -0,0 +1,460 @@
+package csm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func CreateDeployment(clientset kubernetes.Interface, namespace string, name string, image string) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: v1.PodSpec{Containers: []v1.Container{{Name: name, Image: image}}}}}}
+	resp, _ := clientset.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, meta_v1.CreateOptions{})
+	return resp
+}
+
+func CreateRunningPod(clientset kubernetes.Interface, namespace string, name string, containers ...v1.Container) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: namespace}, Spec: v1.PodSpec{Containers: containers},
+		Status: v1.PodStatus{Phase: v1.PodRunning}}
+	resp, _ := clientset.CoreV1().Pods(namespace).Create(context.TODO(), pod, meta_v1.CreateOptions{})
+	return resp
+}
+
+func TestModuleEnabled(t *testing.T) {
+	type tests = []struct {
+		description string
+		modules     []string
+		expected    bool
+	}
+	var moduleEnabledTests = tests{
+		{"all modules", nil, true},
+		{"module selected", []string{"authorization"}, true},
+		{"module not selected", []string{}, false},
+	}
+	defer SetModules(nil)
+	for _, test := range moduleEnabledTests {
+		t.Run(test.description, func(t *testing.T) {
+			SetModules(test.modules)
+			if diff := cmp.Diff(moduleEnabled("authorization"), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestCollectAuthorization(t *testing.T) {
+	type tests = []struct {
+		description        string
+		expectedNamespaces []string
+		expectedFiles      []string
+		expectedSidecars   string
+	}
+	var collectAuthorizationTests = tests{
+		{"authorization proxy server collected", []string{"authorization"},
+			[]string{"proxy-server-1/proxy-server-1-describe.txt", "proxy-server-1/proxy-server/proxy-server-1-proxy-server.txt",
+				"config/configmap/karavi-config.yaml", "config/secret/karavi-config-secret.yaml"},
+			"\tcsi-powerflex/powerflex-controller: true\n\tcsi-powerflex/powerflex-node: false\n"},
+	}
+	for _, test := range collectAuthorizationTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			_ = CreateDeployment(clientset, "authorization", "proxy-server", "dellemc/csm-authorization-proxy:v1.2.0")
+			_ = CreateRunningPod(clientset, "authorization", "proxy-server-1", v1.Container{Name: "proxy-server"})
+			_ = CreateRunningPod(clientset, "authorization", "unrelated-1", v1.Container{Name: "unrelated"})
+			_ = CreateRunningPod(clientset, "csi-powerflex", "powerflex-controller",
+				v1.Container{Name: "driver", Image: "dellemc/csi-vxflexos:v2.2.0"}, v1.Container{Name: authorizationSidecar})
+			_ = CreateRunningPod(clientset, "csi-powerflex", "powerflex-node", v1.Container{Name: "driver", Image: "dellemc/csi-vxflexos:v2.2.0"})
+			cm := &v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: "karavi-config", Namespace: "authorization"}}
+			_, _ = clientset.CoreV1().ConfigMaps("authorization").Create(context.TODO(), cm, meta_v1.CreateOptions{})
+			secret := &v1.Secret{ObjectMeta: meta_v1.ObjectMeta{Name: "karavi-config-secret", Namespace: "authorization"},
+				Data: map[string][]byte{"config.yaml": []byte("jwtsigningsecret")}}
+			_, _ = clientset.CoreV1().Secrets("authorization").Create(context.TODO(), secret, meta_v1.CreateOptions{})
+
+			module, _ := LookupModule("authorization")
+			namespaces := module.Detect()
+			if diff := cmp.Diff(namespaces, test.expectedNamespaces); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedNamespaces, diff)
+				return
+			}
+			moduleDirectoryName := createDirectory("authorization-logs")
+			defer os.RemoveAll(moduleDirectoryName)
+			module.Collect(namespaces[0], moduleDirectoryName, nil)
+
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(moduleDirectoryName + "/" + file); err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+				}
+			}
+			if _, err := os.Stat(moduleDirectoryName + "/unrelated-1"); err == nil {
+				t.Errorf("unrelated pod collected")
+			}
+			data, _ := ioutil.ReadFile(moduleDirectoryName + "/config/secret/karavi-config-secret.yaml")
+			if strings.Contains(string(data), "jwtsigningsecret") || !strings.Contains(string(data), "config.yaml: <redacted>") {
+				t.Errorf("secret not redacted: %s", data)
+			}
+			data, _ = ioutil.ReadFile(moduleDirectoryName + "/sidecars.txt")
+			if !strings.HasSuffix(string(data), test.expectedSidecars) {
+				t.Errorf("sidecars differ, got: %s", data)
+			}
+		})
+	}
+}
+
+// metricsResponse serves the metrics of the fake API server proxy
+type metricsResponse struct {
+	data string
+}
+
+func (m metricsResponse) DoRaw(context.Context) ([]byte, error) {
+	return []byte(m.data), nil
+}
+
+func (m metricsResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewBufferString(m.data)), nil
+}
+
+func TestCollectObservability(t *testing.T) {
+	type tests = []struct {
+		description     string
+		scrapeMetrics   bool
+		expectedFiles   []string
+		unexpectedFiles []string
+	}
+	var collectObservabilityTests = tests{
+		{"logs and metrics collected", true,
+			[]string{"karavi-metrics-powerflex-1/karavi-metrics-powerflex/karavi-metrics-powerflex-1-karavi-metrics-powerflex.txt",
+				"config/configmap/karavi-metrics-powerflex-configmap.yaml", "metrics/karavi-metrics-powerflex-8080.txt"},
+			[]string{"metrics/karavi-topology-8443.txt"}},
+		{"metrics not scraped", false, nil, []string{"metrics"}},
+	}
+	defer SetScrapeMetrics(false)
+	for _, test := range collectObservabilityTests {
+		t.Run(test.description, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			clientset = client
+			client.PrependProxyReactor("services", func(action k8stesting.Action) (bool, restclient.ResponseWrapper, error) {
+				return true, metricsResponse{data: "powerflex_export_node_read_bw_megabytes_per_second 0\n"}, nil
+			})
+			SetScrapeMetrics(test.scrapeMetrics)
+			_ = CreateDeployment(clientset, "karavi", "karavi-metrics-powerflex", "dellemc/csm-metrics-powerflex:v1.1.0")
+			_ = CreateRunningPod(clientset, "karavi", "karavi-metrics-powerflex-1", v1.Container{Name: "karavi-metrics-powerflex"})
+			cm := &v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: "karavi-metrics-powerflex-configmap", Namespace: "karavi"}}
+			_, _ = clientset.CoreV1().ConfigMaps("karavi").Create(context.TODO(), cm, meta_v1.CreateOptions{})
+			for name, port := range map[string]int32{"karavi-metrics-powerflex": 8080, "karavi-topology": 8443} {
+				service := &v1.Service{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "karavi"},
+					Spec: v1.ServiceSpec{Ports: []v1.ServicePort{{Port: port}}}}
+				_, _ = clientset.CoreV1().Services("karavi").Create(context.TODO(), service, meta_v1.CreateOptions{})
+			}
+
+			module, _ := LookupModule("observability")
+			namespaces := module.Detect()
+			if diff := cmp.Diff(namespaces, []string{"karavi"}); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", namespaces, diff)
+				return
+			}
+			moduleDirectoryName := createDirectory("observability-logs")
+			defer os.RemoveAll(moduleDirectoryName)
+			module.Collect("karavi", moduleDirectoryName, nil)
+
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(moduleDirectoryName + "/" + file); err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+				}
+			}
+			for _, file := range test.unexpectedFiles {
+				if _, err := os.Stat(moduleDirectoryName + "/" + file); err == nil {
+					t.Errorf("file %s not expected", file)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectReplication(t *testing.T) {
+	type tests = []struct {
+		description   string
+		expectedFiles []string
+		expected      []string
+	}
+	var collectReplicationTests = tests{
+		{"controller, sidecars, replication groups and storage classes collected",
+			[]string{"dell-replication-controller-manager-1/manager/dell-replication-controller-manager-1-manager.txt",
+				"sidecars/powerstore/powerstore-controller-1/dell-csi-replicator/powerstore-controller-1-dell-csi-replicator.txt",
+				"dellcsireplicationgroup/rg-1.yaml", "storageclass/powerstore-replication.yaml"},
+			[]string{"rg-1  csi-powerstore.dellemc.com  Ready  SYNCHRONIZED  true    target-cluster  SYNC_COMPLETE",
+				"Image is ready", "replication.storage.dell.com/isReplicationEnabled=true"}},
+	}
+	for _, test := range collectReplicationTests {
+		t.Run(test.description, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			clientset = client
+			// the cluster serves the replication groups at a version other than the default one
+			client.Resources = []*meta_v1.APIResourceList{{GroupVersion: "replication.storage.dell.com/v1",
+				APIResources: []meta_v1.APIResource{{Name: "dellcsireplicationgroups"}}}}
+			rg := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "replication.storage.dell.com/v1",
+				"kind":       "DellCSIReplicationGroup",
+				"metadata":   map[string]interface{}{"name": "rg-1"},
+				"spec":       map[string]interface{}{"driverName": "csi-powerstore.dellemc.com", "remoteClusterId": "target-cluster"},
+				"status": map[string]interface{}{"state": "Ready",
+					"replicationLinkState": map[string]interface{}{"state": "SYNCHRONIZED", "isSource": true},
+					"lastAction":           map[string]interface{}{"condition": "SYNC_COMPLETE"},
+					"conditions":           []interface{}{map[string]interface{}{"condition": "Image is ready", "time": "2022-03-01T10:00:00Z"}}},
+			}}
+			dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{replicationGroupResource.GroupResource().WithVersion("v1"): "DellCSIReplicationGroupList"}, rg)
+			defer func() { dynamicClient = nil }()
+
+			_ = CreateDeployment(clientset, "dell-replication-controller", "dell-replication-controller-manager", "dellemc/dell-replication-controller:v1.2.0")
+			_ = CreateRunningPod(clientset, "dell-replication-controller", "dell-replication-controller-manager-1", v1.Container{Name: "manager"})
+			_ = CreateRunningPod(clientset, "powerstore", "powerstore-controller-1", v1.Container{Name: "driver"}, v1.Container{Name: "dell-csi-replicator"})
+			for name, parameters := range map[string]map[string]string{
+				"powerstore-replication": {"replication.storage.dell.com/isReplicationEnabled": "true"},
+				"powerstore":             {"arrayID": "PS000000000001"},
+			} {
+				storageClass := &storagev1.StorageClass{ObjectMeta: meta_v1.ObjectMeta{Name: name}, Provisioner: "csi-powerstore.dellemc.com", Parameters: parameters}
+				_, _ = clientset.StorageV1().StorageClasses().Create(context.TODO(), storageClass, meta_v1.CreateOptions{})
+			}
+
+			module, _ := LookupModule("replication")
+			namespaces := module.Detect()
+			if diff := cmp.Diff(namespaces, []string{"dell-replication-controller"}); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", namespaces, diff)
+				return
+			}
+			moduleDirectoryName := createDirectory("replication-logs")
+			defer os.RemoveAll(moduleDirectoryName)
+			module.Collect("dell-replication-controller", moduleDirectoryName, nil)
+			module.CollectCluster(moduleDirectoryName, nil)
+
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(moduleDirectoryName + "/" + file); err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+				}
+			}
+			if _, err := os.Stat(moduleDirectoryName + "/storageclass/powerstore.yaml"); err == nil {
+				t.Errorf("storage class without replication parameters collected")
+			}
+			data, _ := ioutil.ReadFile(moduleDirectoryName + "/dellcsireplicationgroup/rg-1.yaml")
+			if !strings.Contains(string(data), "apiVersion: replication.storage.dell.com/v1\n") {
+				t.Errorf("replication group not collected at the served version:\n%s", data)
+			}
+			var summary string
+			for _, file := range []string{"replicationgroups.txt", "storageclasses.txt"} {
+				data, _ := ioutil.ReadFile(moduleDirectoryName + "/" + file)
+				summary += string(data)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(summary, expected) {
+					t.Errorf("summary does not contain %q:\n%s", expected, summary)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectReplicatorSidecarsOnce(t *testing.T) {
+	t.Run("sidecars collected once for several controller namespaces", func(t *testing.T) {
+		clientset = fake.NewSimpleClientset()
+		for _, namespace := range []string{"dell-replication-controller", "replication-controller-2"} {
+			_ = CreateDeployment(clientset, namespace, "dell-replication-controller-manager", "dellemc/dell-replication-controller:v1.2.0")
+		}
+		_ = CreateRunningPod(clientset, "powerstore", "powerstore-controller-1", v1.Container{Name: "driver"}, v1.Container{Name: "dell-csi-replicator"})
+
+		module, _ := LookupModule("replication")
+		moduleDirectoryName := createDirectory("replication-sidecar-logs")
+		defer os.RemoveAll(moduleDirectoryName)
+		collectModuleNamespaces(module, moduleDirectoryName, nil)
+
+		var sidecars []string
+		_ = filepath.Walk(moduleDirectoryName, func(path string, info os.FileInfo, err error) error {
+			if err == nil && strings.HasSuffix(path, "-dell-csi-replicator.txt") {
+				sidecars = append(sidecars, path)
+			}
+			return err
+		})
+		expected := []string{moduleDirectoryName + "/sidecars/powerstore/powerstore-controller-1/dell-csi-replicator/powerstore-controller-1-dell-csi-replicator.txt"}
+		if diff := cmp.Diff(sidecars, expected); diff != "" {
+			t.Errorf("%T differ (-got, +want): %s", expected, diff)
+		}
+	})
+}
+
+func TestCollectResiliency(t *testing.T) {
+	type tests = []struct {
+		description string
+		file        string
+		expected    []string
+		unexpected  []string
+	}
+	var collectResiliencyTests = tests{
+		{"podmon taints recorded", "taints.txt",
+			[]string{"worker-1  offline.vxflexos.storage.dell.com  NoSchedule"}, []string{"node.kubernetes.io/unreachable"}},
+		{"protected pods listed", "protected-pods.txt",
+			[]string{"default    app-1  csi-vxflexos  worker-1  Running"}, []string{"app-2"}},
+	}
+	clientset = fake.NewSimpleClientset()
+	_ = CreateRunningPod(clientset, "vxflexos", "vxflexos-controller-1", v1.Container{Name: "driver"}, v1.Container{Name: "podmon"})
+	node := &v1.Node{ObjectMeta: meta_v1.ObjectMeta{Name: "worker-1"}, Spec: v1.NodeSpec{Taints: []v1.Taint{
+		{Key: "offline.vxflexos.storage.dell.com", Effect: v1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/unreachable", Effect: v1.TaintEffectNoExecute},
+	}}}
+	_, _ = clientset.CoreV1().Nodes().Create(context.TODO(), node, meta_v1.CreateOptions{})
+	for name, labels := range map[string]map[string]string{"app-1": {"podmon.dellemc.com/driver": "csi-vxflexos"}, "app-2": nil} {
+		pod := &v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Spec: v1.PodSpec{NodeName: "worker-1"}, Status: v1.PodStatus{Phase: v1.PodRunning}}
+		_, _ = clientset.CoreV1().Pods("default").Create(context.TODO(), pod, meta_v1.CreateOptions{})
+	}
+
+	module, _ := LookupModule("resiliency")
+	namespaces := module.Detect()
+	if diff := cmp.Diff(namespaces, []string{"vxflexos"}); diff != "" {
+		t.Fatalf("%T differ (-got, +want): %s", namespaces, diff)
+	}
+	moduleDirectoryName := createDirectory("resiliency-logs")
+	defer os.RemoveAll(moduleDirectoryName)
+	module.Collect("vxflexos", moduleDirectoryName, nil)
+
+	for _, test := range collectResiliencyTests {
+		t.Run(test.description, func(t *testing.T) {
+			data, err := ioutil.ReadFile(moduleDirectoryName + "/" + test.file)
+			if err != nil {
+				t.Fatalf("file %s not collected: %s", test.file, err)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(string(data), expected) {
+					t.Errorf("%s does not contain %q:\n%s", test.file, expected, data)
+				}
+			}
+			for _, unexpected := range test.unexpected {
+				if strings.Contains(string(data), unexpected) {
+					t.Errorf("%s contains %q:\n%s", test.file, unexpected, data)
+				}
+			}
+		})
+	}
+}
+
+func TestPodmonLogs(t *testing.T) {
+	clientset = fake.NewSimpleClientset()
+	pod := CreateRunningPod(clientset, "vxflexos", "vxflexos-node-1", v1.Container{Name: "driver"}, v1.Container{Name: "podmon"})
+	namespaceDirectoryName := createDirectory("podmon-logs")
+	defer os.RemoveAll(namespaceDirectoryName)
+
+	s := StorageNameSpaceStruct{namespaceName: "vxflexos"}
+	s.GetRunningPods(namespaceDirectoryName, pod, nil, "false")
+
+	if _, err := os.Stat(namespaceDirectoryName + "/vxflexos-node-1/podmon/vxflexos-node-1-podmon.txt"); err != nil {
+		t.Errorf("podmon logs not collected: %s", err)
+	}
+	if _, err := os.Stat(namespaceDirectoryName + "/vxflexos-node-1/driver/vxflexos-node-1-driver.txt"); err == nil {
+		t.Errorf("driver logs collected without the optional flag")
+	}
+}
+
+func TestCollectOperator(t *testing.T) {
+	type tests = []struct {
+		description   string
+		targets       []CollectionTarget
+		expectedFiles []string
+		expected      []string
+	}
+	var collectOperatorTests = tests{
+		{"driver of the module collected", []CollectionTarget{{Namespace: "powerstore", DriverName: "powerstore"}},
+			[]string{"dell-csm-operator-controller-manager-1/manager/dell-csm-operator-controller-manager-1-manager.txt",
+				"powerstore/containerstoragemodule/powerstore.yaml"},
+			[]string{"powerstore  powerstore  powerstore   v2.2.0          Succeeded  powerstore/"}},
+		{"driver of the module not collected", nil,
+			[]string{"powerstore/containerstoragemodule/powerstore.yaml"},
+			[]string{"powerstore  powerstore  powerstore   v2.2.0          Succeeded  not collected"}},
+	}
+	defer func() { collectionTargets = nil }()
+	for _, test := range collectOperatorTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			module := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "storage.dell.com/v1alpha1",
+				"kind":       "ContainerStorageModule",
+				"metadata":   map[string]interface{}{"name": "powerstore", "namespace": "powerstore"},
+				"spec":       map[string]interface{}{"driver": map[string]interface{}{"csiDriverType": "powerstore", "configVersion": "v2.2.0"}},
+				"status":     map[string]interface{}{"state": "Succeeded"},
+			}}
+			dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{containerStorageModuleResource: "ContainerStorageModuleList"}, module)
+			defer func() { dynamicClient = nil }()
+			collectionTargets = test.targets
+
+			_ = CreateDeployment(clientset, "dell-csm-operator", "dell-csm-operator-controller-manager", "dellemc/dell-csm-operator:v1.0.0")
+			_ = CreateRunningPod(clientset, "dell-csm-operator", "dell-csm-operator-controller-manager-1", v1.Container{Name: "manager"})
+
+			csmModule, _ := LookupModule("operator")
+			namespaces := csmModule.Detect()
+			if diff := cmp.Diff(namespaces, []string{"dell-csm-operator"}); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", namespaces, diff)
+				return
+			}
+			moduleDirectoryName := createDirectory("operator-logs")
+			defer os.RemoveAll(moduleDirectoryName)
+			csmModule.Collect("dell-csm-operator", moduleDirectoryName, nil)
+
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(moduleDirectoryName + "/" + file); err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+				}
+			}
+			data, _ := ioutil.ReadFile(moduleDirectoryName + "/containerstoragemodules.txt")
+			for _, expected := range test.expected {
+				if !strings.Contains(string(data), expected) {
+					t.Errorf("summary does not contain %q:\n%s", expected, data)
+				}
+			}
+		})
+	}
+}
+
+func TestServedResource(t *testing.T) {
+	type tests = []struct {
+		description string
+		resources   []*meta_v1.APIResourceList
+		expected    schema.GroupVersionResource
+	}
+	var servedResourceTests = tests{
+		{"served version discovered", []*meta_v1.APIResourceList{
+			{GroupVersion: "replication.storage.dell.com/v1", APIResources: []meta_v1.APIResource{{Name: "dellcsireplicationgroups"}}}},
+			replicationGroupResource.GroupResource().WithVersion("v1")},
+		{"resource served by another version of the group", []*meta_v1.APIResourceList{
+			{GroupVersion: "replication.storage.dell.com/v2", APIResources: []meta_v1.APIResource{{Name: "otherresources"}}},
+			{GroupVersion: "replication.storage.dell.com/v1", APIResources: []meta_v1.APIResource{{Name: "dellcsireplicationgroups"}}}},
+			replicationGroupResource.GroupResource().WithVersion("v1")},
+		{"resource not served", nil, replicationGroupResource},
+	}
+	for _, test := range servedResourceTests {
+		t.Run(test.description, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.Resources = test.resources
+			clientset = client
+			if diff := cmp.Diff(servedResource(replicationGroupResource), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"bufio"
+	"context"
+	utils "csm-logcollector/utils"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// NodeCommand is a diagnostic command run on the host of the cluster nodes
+type NodeCommand struct {
+	// File is the name of the file of the node directory holding the command output
+	File string
+	// Command is the shell command run on the host
+	Command string
+}
+
+// kubeletJournalLines caps the kubelet journal when the logs are not limited to a date range
+const kubeletJournalLines = 50000
+
+// commonNodeCommands are run on every node whichever the drivers collected
+var commonNodeCommands = []NodeCommand{
+	{File: "kubelet.txt", Command: "journalctl -u kubelet --no-pager"},
+	{File: "messages.txt", Command: "tail -n 10000 /var/log/messages 2>/dev/null || tail -n 10000 /var/log/syslog"},
+	{File: "multipath.txt", Command: "multipath -ll"},
+	{File: "mounts.txt", Command: "cat /proc/mounts"},
+	{File: "dmesg.txt", Command: "dmesg -T"},
+}
+
+// transport specific node commands registered by the drivers using them
+var (
+	iscsiSessionsCommand  = NodeCommand{File: "iscsi-sessions.txt", Command: "iscsiadm -m session -P 3"}
+	nvmeSubsystemsCommand = NodeCommand{File: "nvme-subsystems.txt", Command: "nvme list-subsys"}
+	nfsMountsCommand      = NodeCommand{File: "nfs-mounts.txt", Command: "mount -t nfs,nfs4"}
+	sdcGUIDCommand        = NodeCommand{File: "sdc-guid.txt", Command: "/opt/emc/scaleio/sdc/bin/drv_cfg --query_guid"}
+	sdcMDMsCommand        = NodeCommand{File: "sdc-mdms.txt", Command: "/opt/emc/scaleio/sdc/bin/drv_cfg --query_mdms"}
+)
+
+// NodeDiagnostics configures the collection of the host diagnostics of the cluster nodes
+type NodeDiagnostics struct {
+	// Enabled enables the collection of the node diagnostics
+	Enabled bool
+	// DebugPods runs the commands in privileged debug pods instead of SSH
+	DebugPods bool
+	// DebugImage is the image of the debug pods
+	DebugImage string
+	// NodeSelector is the label selector of the nodes collected, all the nodes are collected when empty
+	NodeSelector string
+}
+
+var nodeDiagnostics NodeDiagnostics
+
+// SetNodeDiagnostics configures the collection of the host diagnostics of the cluster nodes
+func SetNodeDiagnostics(diagnostics NodeDiagnostics) {
+	if diagnostics.DebugImage == "" {
+		diagnostics.DebugImage = DefaultDebugImage
+	}
+	nodeDiagnostics = diagnostics
+}
+
+// nodeExecutor runs the diagnostic commands on the host of a node, the output of the commands is streamed
+// to the writer, which is safe for the concurrent writes of the standard output and error
+type nodeExecutor interface {
+	Run(command string, output io.Writer) error
+	Close() error
+}
+
+// getNodeGroups and newNodeExecutor read the node groups and connect to the host of the node,
+// they are replaced by the unit tests
+var (
+	getNodeGroups   = utils.GetNodeGroups
+	newNodeExecutor = func(node corev1.Node, nodeGroups []utils.NodeGroup) (nodeExecutor, error) {
+		if nodeDiagnostics.DebugPods {
+			return newDebugPodExecutor(node, debugPodNamespace())
+		}
+		return newSSHExecutor(node, nodeGroups)
+	}
+)
+
+// sshExecutor runs the commands over an SSH connection with the node
+type sshExecutor struct {
+	client *ssh.Client
+}
+
+// newSSHExecutor connects to the internal address of the node with the credentials of its node group
+func newSSHExecutor(node corev1.Node, nodeGroups []utils.NodeGroup) (nodeExecutor, error) {
+	group, ok := utils.MatchNodeGroup(nodeGroups, node.Name)
+	if !ok {
+		return nil, fmt.Errorf("no node group of config.yml matches node %s", node.Name)
+	}
+	address := nodeAddress(node)
+	if address == "" {
+		return nil, fmt.Errorf("no address found for node %s", node.Name)
+	}
+	client, err := utils.DialSSH(group.SSHConfig, address)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to node %s with node group %s failed with error: %s", node.Name, group.Name, err.Error())
+	}
+	return sshExecutor{client: client}, nil
+}
+
+func (e sshExecutor) Run(command string, output io.Writer) error {
+	return utils.RunCommand(e.client, command, output)
+}
+
+func (e sshExecutor) Close() error {
+	return e.client.Close()
+}
+
+// nodeAddress returns the internal IP of the node, or its host name when none is reported
+func nodeAddress(node corev1.Node) string {
+	hostName := ""
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case corev1.NodeInternalIP:
+			return address.Address
+		case corev1.NodeHostName:
+			hostName = address.Address
+		}
+	}
+	return hostName
+}
+
+// collectNodeDiagnostics runs the diagnostic commands of the collected drivers on the host of every selected
+// node, over SSH or in debug pods, the outputs are written next to the node describe
+func collectNodeDiagnostics(clusterDirectoryName string, dateRange *metav1.Time) {
+	if !nodeDiagnostics.Enabled {
+		return
+	}
+	fmt.Println("\n\nCollecting node diagnostics..........")
+	var nodeGroups []utils.NodeGroup
+	if nodeDiagnostics.DebugPods {
+		// the debug pods are deleted also when the application exits on an error or an interrupt
+		logrus.RegisterExitHandler(cleanupDebugPods)
+		defer cleanupDebugPods()
+	} else {
+		nodeGroups = getNodeGroups()
+		if len(nodeGroups) == 0 {
+			fmt.Println("\tNo node group found in the node_diagnostics section of config.yml, node diagnostics will not be collected")
+			return
+		}
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: nodeDiagnostics.NodeSelector})
+	if err != nil {
+		reportErrors("Collecting node diagnostics", err)
+		return
+	}
+	commands := nodeCommands(dateRange)
+	pool := newWorkerPool()
+	for _, node := range nodes.Items {
+		node := node
+		nodeDirectoryName := createDirectory(clusterDirectoryName + "/nodes/" + node.Name)
+		pool.Go(func() error {
+			executor, err := newNodeExecutor(node, nodeGroups)
+			if err != nil {
+				return err
+			}
+			err = runNodeCommands(executor, commands, nodeDirectoryName)
+			if closeErr := executor.Close(); err == nil {
+				err = closeErr
+			}
+			return err
+		})
+	}
+	reportErrors("Collecting node diagnostics", pool.Wait())
+}
+
+// debugPodNamespace returns the namespace of the first driver collected, where privileged pods are admitted
+func debugPodNamespace() string {
+	if len(collectionTargets) == 0 {
+		return "default"
+	}
+	return collectionTargets[0].Namespace
+}
+
+// nodeCommands returns the common node commands followed by those of the collected drivers,
+// the kubelet journal is limited to the date range, or to its last lines without date range
+func nodeCommands(dateRange *metav1.Time) []NodeCommand {
+	var commands []NodeCommand
+	files := make(map[string]bool)
+	add := func(nodeCommands []NodeCommand) {
+		for _, command := range nodeCommands {
+			if !files[command.File] {
+				files[command.File] = true
+				commands = append(commands, command)
+			}
+		}
+	}
+	add(commonNodeCommands)
+	for _, target := range collectionTargets {
+		driver, _ := LookupDriver(target.DriverName)
+		add(driver.NodeCommands)
+	}
+	for i, command := range commands {
+		if command.File != "kubelet.txt" {
+			continue
+		}
+		if dateRange != nil && !dateRange.IsZero() {
+			commands[i].Command = fmt.Sprintf("%s --since @%d", command.Command, dateRange.Unix())
+		} else {
+			commands[i].Command = fmt.Sprintf("%s -n %d", command.Command, kubeletJournalLines)
+		}
+	}
+	return commands
+}
+
+// runNodeCommands streams the output of every command into its file of the node directory, a failing
+// command is recorded in its file and does not stop the other commands
+func runNodeCommands(executor nodeExecutor, commands []NodeCommand, nodeDirectoryName string) error {
+	var errs []error
+	for _, command := range commands {
+		if err := runNodeCommand(executor, command, nodeDirectoryName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// runNodeCommand streams the output of the command into its file, the write errors are returned
+func runNodeCommand(executor nodeExecutor, command NodeCommand, nodeDirectoryName string) error {
+	filePath := filepath.Clean(nodeDirectoryName + "/" + command.File)
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("creating file %s failed with error: %s", filePath, err.Error())
+	}
+	buffered := bufio.NewWriter(f)
+	w := &syncWriter{writer: buffered}
+	fmt.Fprintf(w, "$ %s\n", command.Command)
+	if err := executor.Run(command.Command, w); err != nil {
+		fmt.Fprintf(w, "\ncommand failed with error: %s\n", err.Error())
+	}
+	err = buffered.Flush()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing file %s failed with error: %s", filePath, err.Error())
+	}
+	return nil
+}
+
+// syncWriter serializes the writes of the standard output and error of a command
+type syncWriter struct {
+	sync.Mutex
+	writer io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	return w.writer.Write(p)
+}
@@ -0,0 +1,119 @@
+package csm
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	utils "csm-logcollector/utils"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeExecutor returns the output of the known commands and fails the others
+type fakeExecutor struct {
+	outputs map[string]string
+}
+
+func (e fakeExecutor) Run(command string, output io.Writer) error {
+	for prefix, commandOutput := range e.outputs {
+		if strings.HasPrefix(command, prefix) {
+			_, err := io.WriteString(output, commandOutput)
+			return err
+		}
+	}
+	_, _ = io.WriteString(output, "command not found")
+	return errors.New("exit status 127")
+}
+
+func (e fakeExecutor) Close() error {
+	return nil
+}
+
+func TestCollectNodeDiagnostics(t *testing.T) {
+	type tests = []struct {
+		description string
+		driverName  string
+		expected    map[string]string
+		unexpected  []string
+	}
+	var collectNodeDiagnosticsTests = tests{
+		{"iscsi and nvme commands of powermax", "powermax",
+			map[string]string{"kubelet.txt": "kubelet started", "iscsi-sessions.txt": "iSCSI Transport Class",
+				"nvme-subsystems.txt": "command failed with error: exit status 127"},
+			[]string{"nfs-mounts.txt", "sdc-guid.txt"}},
+		{"sdc commands of powerflex", "powerflex",
+			map[string]string{"multipath.txt": "mpatha", "sdc-guid.txt": "A1B2C3D4"},
+			[]string{"iscsi-sessions.txt"}},
+	}
+	SetNodeDiagnostics(NodeDiagnostics{Enabled: true})
+	defer SetNodeDiagnostics(NodeDiagnostics{})
+	defer func(groups func() []utils.NodeGroup, executor func(v1.Node, []utils.NodeGroup) (nodeExecutor, error)) {
+		getNodeGroups, newNodeExecutor = groups, executor
+	}(getNodeGroups, newNodeExecutor)
+	getNodeGroups = func() []utils.NodeGroup {
+		return []utils.NodeGroup{{Name: "all", SSHConfig: utils.SSHConfig{Username: "root"}}}
+	}
+	newNodeExecutor = func(node v1.Node, nodeGroups []utils.NodeGroup) (nodeExecutor, error) {
+		return fakeExecutor{outputs: map[string]string{"journalctl": "kubelet started", "iscsiadm": "iSCSI Transport Class",
+			"multipath": "mpatha", "/opt/emc/scaleio/sdc/bin/drv_cfg --query_guid": "A1B2C3D4"}}, nil
+	}
+	for _, test := range collectNodeDiagnosticsTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			_ = CreateNodes(clientset, "worker-1")
+			collectionTargets = []CollectionTarget{{Namespace: "csi-" + test.driverName, DriverName: test.driverName}}
+			defer func() { collectionTargets = nil }()
+
+			clusterDirectoryName := createDirectory("node-diagnostics-logs")
+			defer os.RemoveAll(clusterDirectoryName)
+			collectNodeDiagnostics(clusterDirectoryName, &meta_v1.Time{})
+
+			for file, expected := range test.expected {
+				data, err := ioutil.ReadFile(clusterDirectoryName + "/nodes/worker-1/" + file)
+				if err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+					continue
+				}
+				if !strings.Contains(string(data), expected) {
+					t.Errorf("file %s does not contain %q:\n%s", file, expected, data)
+				}
+			}
+			for _, file := range test.unexpected {
+				if _, err := os.Stat(clusterDirectoryName + "/nodes/worker-1/" + file); err == nil {
+					t.Errorf("file %s not expected", file)
+				}
+			}
+		})
+	}
+}
+
+func TestNodeCommands(t *testing.T) {
+	type tests = []struct {
+		description string
+		dateRange   *meta_v1.Time
+		expected    string
+	}
+	sinceTime := meta_v1.Unix(1650000000, 0)
+	var nodeCommandsTests = tests{
+		{"kubelet journal capped without date range", &meta_v1.Time{}, "journalctl -u kubelet --no-pager -n 50000"},
+		{"kubelet journal limited to date range", &sinceTime, "journalctl -u kubelet --no-pager --since @1650000000"},
+	}
+	for _, test := range nodeCommandsTests {
+		t.Run(test.description, func(t *testing.T) {
+			commands := nodeCommands(test.dateRange)
+			if diff := cmp.Diff(commands[0].Command, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+			if diff := cmp.Diff(commonNodeCommands[0].Command, "journalctl -u kubelet --no-pager"); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
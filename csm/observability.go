@@ -0,0 +1,98 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// observabilityServices are the deployments of CSM Observability
+var observabilityServices = []string{"karavi-metrics-", "csm-metrics-", "karavi-topology", "otel-collector"}
+
+// scrapeMetrics enables the snapshot of the metrics exported by the observability services
+var scrapeMetrics bool
+
+// SetScrapeMetrics enables the scraping of the /metrics endpoints of the observability services
+func SetScrapeMetrics(scrape bool) {
+	scrapeMetrics = scrape
+}
+
+func init() {
+	RegisterModule(Module{
+		Name:        "observability",
+		DisplayName: "Observability",
+		Detect: func() []string {
+			return detectDeployments(observabilityServices...)
+		},
+		Collect: collectObservability,
+	})
+}
+
+// collectObservability collects the logs and config of the metrics, topology and OpenTelemetry
+// collector services, along with a snapshot of the exported metrics when opted
+func collectObservability(namespace string, moduleDirectoryName string, dateRange *metav1.Time) {
+	collectModulePods(namespace, moduleDirectoryName, dateRange, observabilityServices...)
+	collectModuleConfig(namespace, moduleDirectoryName, append(observabilityServices, "karavi-authorization-config")...)
+	if scrapeMetrics {
+		collectMetrics(namespace, moduleDirectoryName)
+	}
+}
+
+// collectMetrics scrapes the /metrics endpoint of every port of the metrics services through the API server proxy
+func collectMetrics(namespace string, moduleDirectoryName string) {
+	fmt.Println("\n\nCollecting metrics..........")
+	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		reportErrors("Getting services in namespace "+namespace, err)
+		return
+	}
+	metricsDirectoryName := createDirectory(moduleDirectoryName + "/metrics")
+	pool := newWorkerPool()
+	for _, service := range services.Items {
+		if !strings.Contains(service.Name, "metrics-") {
+			continue
+		}
+		for _, port := range service.Spec.Ports {
+			service, port := service, port
+			pool.Go(func() error {
+				return scrapeServiceMetrics(namespace, service.Name, port, metricsDirectoryName)
+			})
+		}
+	}
+	reportErrors("Collecting metrics in namespace "+namespace, pool.Wait())
+}
+
+func scrapeServiceMetrics(namespace string, serviceName string, port corev1.ServicePort, metricsDirectoryName string) error {
+	scheme := "http"
+	if strings.Contains(port.Name, "https") || port.Port == 443 {
+		scheme = "https"
+	}
+	portNumber := strconv.Itoa(int(port.Port))
+	response := clientset.CoreV1().Services(namespace).ProxyGet(scheme, serviceName, portNumber, "/metrics", nil)
+	if response == nil {
+		return fmt.Errorf("proxy to service %s port %s is not available", serviceName, portNumber)
+	}
+	data, err := response.DoRaw(context.TODO())
+	if err != nil {
+		return fmt.Errorf("scraping metrics of service %s port %s failed with error: %s", serviceName, portNumber, err.Error())
+	}
+	filename := serviceName + "-" + portNumber + ".txt"
+	return writeSanitized(metricsDirectoryName, filename, data, getSensitiveContent(namespace))
+}
@@ -0,0 +1,99 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"bytes"
+	"context"
+	utils "csm-logcollector/utils"
+	"fmt"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// containerStorageModuleResource is the ContainerStorageModule custom resource of the CSM Operator,
+// the version served by the cluster is resolved through discovery
+var containerStorageModuleResource = schema.GroupVersionResource{Group: "storage.dell.com", Version: "v1alpha1", Resource: "containerstoragemodules"}
+
+func init() {
+	RegisterModule(Module{
+		Name:        "operator",
+		DisplayName: "Operator",
+		Detect: func() []string {
+			return detectDeployments("dell-csm-operator")
+		},
+		Collect: collectOperator,
+	})
+}
+
+// collectOperator collects the logs of the operator controller and the ContainerStorageModule objects
+// of all the namespaces, along with the bundle folder of the driver deployed by each of them
+func collectOperator(namespace string, moduleDirectoryName string, dateRange *metav1.Time) {
+	collectModulePods(namespace, moduleDirectoryName, dateRange)
+	collectModuleConfig(namespace, moduleDirectoryName, "dell-csm-operator")
+
+	if dynamicClient == nil {
+		reportErrors("Getting container storage modules", fmt.Errorf("dynamic client is not configured"))
+		return
+	}
+	resource := servedResource(containerStorageModuleResource)
+	modules, err := dynamicClient.Resource(resource).Namespace("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		reportErrors("Getting container storage modules", fmt.Errorf("getting container storage modules failed with error: %s", err.Error()))
+		return
+	}
+	fmt.Printf("\t%d container storage modules collected\n", len(modules.Items))
+
+	// the objects are sanitized against the secrets of the namespace of the driver they deploy
+	for i := range modules.Items {
+		module := &modules.Items[i]
+		if getSensitiveContent(module.GetNamespace()) == nil {
+			setSensitiveContent(module.GetNamespace(), utils.GetSensitiveContent(clientset, module.GetNamespace()))
+		}
+		s := StorageNameSpaceStruct{namespaceName: module.GetNamespace()}
+		inventory := inventoryObjects{kind: "ContainerStorageModule", version: resource.GroupVersion()}
+		inventory.objects = append(inventory.objects, module)
+		if err := s.writeInventory(createDirectory(moduleDirectoryName+"/"+module.GetNamespace()), inventory); err != nil {
+			reportErrors("Collecting container storage modules", err)
+		}
+	}
+	captureLOG(moduleDirectoryName, "containerstoragemodules.txt", formatContainerStorageModules(modules.Items))
+}
+
+// formatContainerStorageModules returns the ContainerStorageModule objects as a table along with
+// the bundle folder of the driver namespace, when the driver is collected
+func formatContainerStorageModules(modules []unstructured.Unstructured) string {
+	collected := make(map[string]bool)
+	for _, target := range collectionTargets {
+		collected[target.Namespace] = true
+	}
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tDRIVER TYPE\tCONFIG VERSION\tSTATE\tDRIVER LOGS")
+	for _, module := range modules {
+		driverType, _, _ := unstructured.NestedString(module.Object, "spec", "driver", "csiDriverType")
+		configVersion, _, _ := unstructured.NestedString(module.Object, "spec", "driver", "configVersion")
+		state, _, _ := unstructured.NestedString(module.Object, "status", "state")
+		driverLogs := "not collected"
+		if collected[module.GetNamespace()] {
+			driverLogs = module.GetNamespace() + "/"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", module.GetNamespace(), module.GetName(), driverType, configVersion, state, driverLogs)
+	}
+	w.Flush()
+	return buf.String()
+}
@@ -14,112 +14,28 @@
 package csm
 
 import (
-	"context"
 	utils "csm-logcollector/utils"
-	"fmt"
-	"strings"
-	"time"
-
-	coordinationv1 "k8s.io/api/coordination/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	describe "k8s.io/kubectl/pkg/describe"
 )
 
-// Logging object
-var psLog, _ = utils.GetLogger()
+func init() {
+	RegisterDriver(Driver{
+		Name:          "powerstore",
+		DisplayName:   "PowerStore",
+		MenuOrder:     3,
+		ImagePatterns: []string{"csi-powerstore"},
+		LeaseName:     powerStoreLeaseName,
+		SecretLayout:  utils.PowerStoreSecretLayout,
+		NodeCommands:  []NodeCommand{iscsiSessionsCommand, nvmeSubsystemsCommand, nfsMountsCommand},
+		New:           func() StorageNameSpace { return PowerStoreStruct{} },
+	})
+}
 
 // PowerStoreStruct for PowerStore platform
 type PowerStoreStruct struct {
 	StorageNameSpaceStruct
 }
 
-// GetLeaseDetails collects lease details
-func (p PowerStoreStruct) GetLeaseDetails() string {
-	fmt.Printf("\n\nLease pod for %s..............\n", p.namespaceName)
-	fmt.Println("=====================================")
-	_ = &coordinationv1.Lease{}
-	leasePodList, err := clientset.CoordinationV1().Leases(p.namespaceName).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		psLog.Fatalf("Getting lease details in namespace %s failed with error: %s", p.namespaceName, err.Error())
-	}
-	var holder string
-	leasepod := "external-attacher-leader-" + p.namespaceName + "-dellemc-com"
-
-	for _, lease := range leasePodList.Items {
-		if strings.Contains(lease.Name, leasepod) {
-			fmt.Printf("\t%s\n", lease.Name)
-			fmt.Printf("\t%s\n", lease.Namespace)
-			fmt.Printf("\t%s\n", *lease.Spec.HolderIdentity) // Points to same controller pod for all instances
-			psLog.Debugf("Lease pod detailes: %s, %s, %s", lease.Name, lease.Namespace, *lease.Spec.HolderIdentity)
-			fmt.Println()
-			holder = *lease.Spec.HolderIdentity
-		}
-	}
-	return holder
-}
-
-// GetLogs accesses the API to get driver/sidecarpod logs of RUNNING pods
-func (p PowerStoreStruct) GetLogs(namespace string, optionalFlag string, noOfDays int, driverStorageSystem int) {
-	p.namespaceName, _, _ = p.GetDriverDetails(namespace, driverStorageSystem)
-	fmt.Println("\n*******************************************************************************")
-	var dirName string
-	t := time.Now().Format("20060102150405") //YYYYMMDDhhmmss
-	dirName = namespace + "_" + t
-	namespaceDirectoryName := createDirectory(dirName)
-	nodeDirectoryName := ""
-
-	//Capturing describe nodes
-	nodes := GetNodes()
-	for _, node := range nodes {
-		dirName = namespaceDirectoryName + "/" + node
-		nodeDirectoryName = createDirectory(dirName)
-		p.DescribeNode(node, describe.DescriberSettings{ShowEvents: true}, nodeDirectoryName)
-	}
-	//Capturing describe pods
-	podarray := p.GetPods()
-	dateRange := GetDateRange(noOfDays)
-	fmt.Printf("Daterange: %s\n", dateRange)
-
-	for _, pod := range podarray {
-		dirName = namespaceDirectoryName + "/" + pod
-		podDirectoryName := createDirectory(dirName)
-		p.DescribePods(pod, describe.DescriberSettings{ShowEvents: true}, podDirectoryName)
-		if optionalFlag == "True" || optionalFlag == "true" {
-			p.DescribePvcs(pod, describe.DescriberSettings{ShowEvents: true}, podDirectoryName)
-		}
-	}
-
-	p.GetLeaseDetails()
-	// access the API to get driver/sidecarpod logs of RUNNING/NOT RUNNING pods
-
-	fmt.Println("\n\nCollecting POD Logs (driver logs, sidecar logs)..........")
-
-	podallns, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		psLog.Fatalf("Getting all pods failed with error: %s", err.Error())
-	}
-	for pod := range podallns.Items {
-		if podallns.Items[pod].Namespace != namespace {
-			continue
-		} else if podallns.Items[pod].Namespace == namespace {
-			if podallns.Items[pod].Status.Phase == RunningPodState {
-				p.GetRunningPods(namespaceDirectoryName, &podallns.Items[pod], &dateRange, optionalFlag)
-			} else {
-				p.GetNonRunningPods(namespaceDirectoryName, &podallns.Items[pod])
-			}
-		}
-	}
-
-	// Perform sanitization
-	ok := utils.PerformSanitization(clientset, namespace, namespaceDirectoryName)
-	if !ok {
-		psLog.Warnf("Sanitization not performed for %s driver.", namespace)
-	}
-
-	errMsg := createTarball(namespaceDirectoryName, ".")
-
-	if errMsg != nil {
-		fmt.Printf("Creating tarball %s failed with error: %s\n", namespaceDirectoryName, errMsg.Error())
-		psLog.Fatalf("Creating tarball %s failed with error: %s", namespaceDirectoryName, errMsg.Error())
-	}
+// powerStoreLeaseName returns the lease name of PowerStore driver, held by the external-attacher sidecar
+func powerStoreLeaseName(namespace string) string {
+	return "external-attacher-leader-" + namespace + "-dellemc-com"
 }
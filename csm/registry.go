@@ -0,0 +1,152 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	utils "csm-logcollector/utils"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Driver holds the details which a CSI driver platform registers with the log collector
+type Driver struct {
+	// Name is the driver name accepted on the command line, e.g. powermax
+	Name string
+	// DisplayName is the storage platform name shown to the user
+	DisplayName string
+	// MenuOrder is the number of the driver in the interactive menu, the drivers without one are listed last
+	MenuOrder int
+	// Aliases are the alternate driver names accepted on the command line
+	Aliases []string
+	// ImagePatterns are the image names of the driver container, e.g. csi-powermax
+	ImagePatterns []string
+	// LeaseName returns the name of the driver lease for the given namespace
+	LeaseName func(namespace string) string
+	// SecretLayout describes the secret/config file of the driver used for the sanitization
+	SecretLayout utils.SecretLayout
+	// Sidecars are the platform specific sidecar containers checked in the driver pods
+	Sidecars []Sidecar
+	// NodeCommands are the platform specific diagnostic commands run on the host of the nodes
+	NodeCommands []NodeCommand
+	// Collectors are run after the driver logs are collected into the namespace directory
+	Collectors []Collector
+	// New returns the log collector of the platform
+	New func() StorageNameSpace
+}
+
+// Sidecar is a platform specific sidecar container whose deployment is checked in the driver pods
+type Sidecar struct {
+	// Name is the name of the sidecar container
+	Name string
+	// Controller checks the sidecar in the controller pod holding the driver lease, otherwise in the node pods
+	Controller bool
+}
+
+// Collector collects platform specific details of the driver namespace into the namespace directory
+type Collector func(s StorageNameSpaceStruct, namespaceDirectoryName string)
+
+var driverRegistry = make(map[string]Driver)
+
+// RegisterDriver registers a CSI driver platform with the log collector
+func RegisterDriver(driver Driver) {
+	if driver.LeaseName == nil {
+		driver.LeaseName = driverLeaseName
+	}
+	driverRegistry[driver.Name] = driver
+	utils.RegisterSecretLayout(driver.SecretLayout)
+}
+
+// GetDrivers returns the registered CSI drivers in the order of the interactive menu, then by name
+func GetDrivers() []Driver {
+	var drivers []Driver
+	for _, driver := range driverRegistry {
+		drivers = append(drivers, driver)
+	}
+	sort.Slice(drivers, func(i, j int) bool {
+		if drivers[i].MenuOrder != drivers[j].MenuOrder {
+			if drivers[i].MenuOrder == 0 || drivers[j].MenuOrder == 0 {
+				return drivers[j].MenuOrder == 0
+			}
+			return drivers[i].MenuOrder < drivers[j].MenuOrder
+		}
+		return drivers[i].Name < drivers[j].Name
+	})
+	return drivers
+}
+
+// LookupDriver returns the registered CSI driver for the given name or alias
+func LookupDriver(name string) (Driver, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, driver := range driverRegistry {
+		if driver.Name == name {
+			return driver, true
+		}
+		for _, alias := range driver.Aliases {
+			if alias == name {
+				return driver, true
+			}
+		}
+	}
+	return Driver{}, false
+}
+
+// driverLeaseName is the lease naming convention followed by most of the drivers
+func driverLeaseName(namespace string) string {
+	return "driver-csi-" + namespace + "-dellemc-com"
+}
+
+// MatchesImage verifies if the given container image belongs to the driver
+func (d Driver) MatchesImage(image string) bool {
+	repository := strings.SplitN(image, "@", 2)[0]
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	for _, pattern := range d.ImagePatterns {
+		if path.Base(repository) == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesName verifies if the given CSIDriver, provisioner or lease name belongs to the driver
+func (d Driver) MatchesName(name string) bool {
+	for _, pattern := range d.ImagePatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// driverForImage returns the registered driver of the given container image
+func driverForImage(image string) (Driver, bool) {
+	for _, driver := range GetDrivers() {
+		if driver.MatchesImage(image) {
+			return driver, true
+		}
+	}
+	return Driver{}, false
+}
+
+// driverForName returns the registered driver of the given CSIDriver, provisioner or lease name
+func driverForName(name string) (Driver, bool) {
+	for _, driver := range GetDrivers() {
+		if driver.MatchesName(name) {
+			return driver, true
+		}
+	}
+	return Driver{}, false
+}
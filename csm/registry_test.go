@@ -0,0 +1,135 @@
+package csm
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLookupDriver(t *testing.T) {
+	type tests = []struct {
+		description    string
+		name           string
+		expectedDriver string
+		expectedFound  bool
+	}
+
+	var lookupTests = tests{
+		{"lookup by name", "powermax", "powermax", true},
+		{"lookup by alias", "Isilon", "powerscale", true},
+		{"lookup unknown driver", "csi-unknown", "", false},
+	}
+
+	for _, test := range lookupTests {
+		t.Run(test.description, func(t *testing.T) {
+			driver, found := LookupDriver(test.name)
+			if diff := cmp.Diff(found, test.expectedFound); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedFound, diff)
+				return
+			}
+			if diff := cmp.Diff(driver.Name, test.expectedDriver); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedDriver, diff)
+				return
+			}
+		})
+	}
+}
+
+func TestGetDrivers(t *testing.T) {
+	type tests = []struct {
+		description string
+		register    []Driver
+		expected    []string
+	}
+	var getDriversTests = tests{
+		{"registered drivers in menu order", nil, []string{"powerscale", "unity", "powerstore", "powermax", "powerflex"}},
+		{"drivers without menu order listed last", []Driver{{Name: "powerstore-nfs"}, {Name: "objectscale"}},
+			[]string{"powerscale", "unity", "powerstore", "powermax", "powerflex", "objectscale", "powerstore-nfs"}},
+	}
+	for _, test := range getDriversTests {
+		t.Run(test.description, func(t *testing.T) {
+			for _, driver := range test.register {
+				RegisterDriver(driver)
+				defer delete(driverRegistry, driver.Name)
+			}
+			var names []string
+			for _, driver := range GetDrivers() {
+				names = append(names, driver.Name)
+			}
+			if diff := cmp.Diff(names, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestRegisterDriver(t *testing.T) {
+	t.Run("register new driver", func(t *testing.T) {
+		RegisterDriver(Driver{Name: "powerstore-nfs", DisplayName: "PowerStore NFS", ImagePatterns: []string{"csi-powerstore-nfs"},
+			New: func() StorageNameSpace { return PowerStoreStruct{} }})
+		defer delete(driverRegistry, "powerstore-nfs")
+
+		driver, found := driverForImage("dellemc/csi-powerstore-nfs:v1.0.0")
+		if !found || driver.Name != "powerstore-nfs" {
+			t.Errorf("registered driver not found for its image, got: %s", driver.Name)
+		}
+		if diff := cmp.Diff(driver.LeaseName("csi-powerstore-nfs"), "driver-csi-csi-powerstore-nfs-dellemc-com"); diff != "" {
+			t.Errorf("default lease name differ (-got, +want): %s", diff)
+		}
+	})
+}
+
+func TestCheckSidecars(t *testing.T) {
+	type tests = []struct {
+		description string
+		driverName  string
+		pod         *v1.Pod
+		expected    []string
+	}
+	controllerPod := &v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Name: "powermax-controller-1"},
+		Spec: v1.PodSpec{Containers: []v1.Container{{Name: "driver"}, {Name: "reverseproxy"}}}}
+	nodePod := &v1.Pod{ObjectMeta: meta_v1.ObjectMeta{Name: "vxflexos-node-1"}, Spec: v1.PodSpec{Containers: []v1.Container{{Name: "driver"}}}}
+	var checkSidecarsTests = tests{
+		{"controller sidecar in lease holder", "powermax", controllerPod, []string{"reverseproxy sidecar is deployed: true for powermax-controller-1"}},
+		{"controller sidecar not checked in node pod", "powermax", nodePod, nil},
+		{"node sidecar missing in node pod", "powerflex", nodePod, []string{"sdc-monitor sidecar is deployed: false for vxflexos-node-1"}},
+	}
+	for _, test := range checkSidecarsTests {
+		t.Run(test.description, func(t *testing.T) {
+			driver, _ := LookupDriver(test.driverName)
+			s := StorageNameSpaceStruct{driver: driver, leaseHolder: "powermax-controller-1"}
+			if diff := cmp.Diff(s.checkSidecars(test.pod), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestDriverCollectors(t *testing.T) {
+	t.Run("registered collector run after the driver logs", func(t *testing.T) {
+		RegisterDriver(Driver{Name: "collectortest", DisplayName: "Collector Test", ImagePatterns: []string{"csi-collectortest"},
+			Collectors: []Collector{func(s StorageNameSpaceStruct, namespaceDirectoryName string) {
+				captureLOG(namespaceDirectoryName, "collector.txt", "collected "+s.namespaceName)
+			}},
+			New: func() StorageNameSpace { return StorageNameSpaceStruct{} }})
+		defer delete(driverRegistry, "collectortest")
+		clientset = fake.NewSimpleClientset()
+		namespaceDirectoryName := createDirectory("collector-logs")
+		defer os.RemoveAll(namespaceDirectoryName)
+
+		var st StorageNameSpaceStruct
+		st.CollectLogs("csi-collectortest", namespaceDirectoryName, "false", nil, "collectortest")
+		data, err := ioutil.ReadFile(namespaceDirectoryName + "/collector.txt")
+		if err != nil {
+			t.Fatalf("collector output not written: %s", err)
+		}
+		if diff := cmp.Diff(string(data), "collected csi-collectortest"); diff != "" {
+			t.Errorf("%T differ (-got, +want): %s", string(data), diff)
+		}
+	})
+}
@@ -0,0 +1,257 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	describe "k8s.io/kubectl/pkg/describe"
+)
+
+// replicatorSidecar is the sidecar injected into the driver controller pods by CSM Replication
+const replicatorSidecar = "dell-csi-replicator"
+
+// replicationParameterPrefix is the prefix of the replication parameters of the storage classes
+const replicationParameterPrefix = "replication.storage.dell.com/"
+
+// replicationGroupResource is the DellCSIReplicationGroup custom resource, the version served by the cluster
+// is resolved through discovery
+var replicationGroupResource = schema.GroupVersionResource{Group: "replication.storage.dell.com", Version: "v1alpha1", Resource: "dellcsireplicationgroups"}
+
+// replicationTargetKubeconfig is the kubeconfig of the target cluster of the replication
+var replicationTargetKubeconfig string
+
+// SetReplicationTarget sets the kubeconfig of the target cluster collected along with the source cluster
+func SetReplicationTarget(kubeconfig string) {
+	replicationTargetKubeconfig = kubeconfig
+}
+
+func init() {
+	RegisterModule(Module{
+		Name:        "replication",
+		DisplayName: "Replication",
+		Detect: func() []string {
+			return detectDeployments("dell-replication-controller")
+		},
+		Collect:        collectReplication,
+		CollectCluster: collectReplicatorSidecars,
+		CollectRemote:  collectReplicationTarget,
+	})
+}
+
+// collectReplication collects the logs of the replication controller, the DellCSIReplicationGroup objects
+// and the storage classes having replication parameters
+func collectReplication(namespace string, moduleDirectoryName string, dateRange *metav1.Time) {
+	collectModulePods(namespace, moduleDirectoryName, dateRange)
+	collectModuleConfig(namespace, moduleDirectoryName, "dell-replication-controller-config")
+
+	s := StorageNameSpaceStruct{namespaceName: namespace}
+	replicationGroups, err := listReplicationGroups()
+	if err != nil {
+		reportErrors("Getting replication groups", err)
+	} else {
+		fmt.Printf("\t%d replication groups collected\n", len(replicationGroups.objects))
+		if err := s.writeInventory(moduleDirectoryName, replicationGroups); err != nil {
+			reportErrors("Collecting replication groups", err)
+		}
+		captureLOG(moduleDirectoryName, "replicationgroups.txt", formatReplicationGroups(replicationGroups.objects))
+	}
+
+	storageClasses, err := listReplicationStorageClasses()
+	if err != nil {
+		reportErrors("Getting replication storage classes", err)
+		return
+	}
+	if err := s.writeInventory(moduleDirectoryName, storageClasses); err != nil {
+		reportErrors("Collecting replication storage classes", err)
+	}
+	captureLOG(moduleDirectoryName, "storageclasses.txt", formatReplicationParameters(storageClasses.objects))
+}
+
+// collectReplicationTarget collects the replication module from the target cluster when its kubeconfig is set
+func collectReplicationTarget(bundleDirectoryName string, dateRange *metav1.Time) {
+	if replicationTargetKubeconfig == "" {
+		return
+	}
+	fmt.Printf("\n\nCollecting CSM Replication in the target cluster %s..........\n", replicationTargetKubeconfig)
+	targetClientset, targetDynamicClient, err := clientsForKubeconfig(replicationTargetKubeconfig)
+	if err != nil {
+		reportErrors("Connecting to the replication target cluster", err)
+		return
+	}
+	restore := useClients(targetClientset, targetDynamicClient)
+	defer restore()
+	module, _ := LookupModule("replication")
+	collectModuleNamespaces(module, bundleDirectoryName+"/modules/replication-target", dateRange)
+}
+
+// clientsForKubeconfig creates the clientset and the dynamic client of the cluster of the kubeconfig
+func clientsForKubeconfig(kubeconfig string) (kubernetes.Interface, dynamic.Interface, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building config object from %s failed with error: %s", kubeconfig, err.Error())
+	}
+	targetClientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building clientset object failed with error: %s", err.Error())
+	}
+	targetDynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building dynamic client object failed with error: %s", err.Error())
+	}
+	return targetClientset, targetDynamicClient, nil
+}
+
+// useClients points the collection to another cluster until the returned function restores the clients,
+// the modules are collected one at a time so that no collection task is running during the switch
+func useClients(targetClientset kubernetes.Interface, targetDynamicClient dynamic.Interface) func() {
+	sourceClientset, sourceDynamicClient := clientset, dynamicClient
+	clientset, dynamicClient = targetClientset, targetDynamicClient
+	return func() {
+		clientset, dynamicClient = sourceClientset, sourceDynamicClient
+	}
+}
+
+// collectReplicatorSidecars collects the logs of the replicator sidecar of the driver pods of all the namespaces
+// under the sidecars directory, once per cluster whichever the number of replication controller namespaces
+func collectReplicatorSidecars(moduleDirectoryName string, dateRange *metav1.Time) {
+	sidecarsDirectoryName := moduleDirectoryName + "/sidecars"
+	podList, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		reportErrors("Getting driver pods with the replicator sidecar", err)
+		return
+	}
+	pool := newWorkerPool()
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !hasContainer(pod, replicatorSidecar) {
+			continue
+		}
+		s := StorageNameSpaceStruct{namespaceName: pod.Namespace}
+		containerDirectoryName := createDirectory(sidecarsDirectoryName + "/" + pod.Namespace + "/" + pod.Name + "/" + replicatorSidecar)
+		writeContainerSummary(pod, replicatorSidecar, containerDirectoryName)
+		pool.Go(func() error {
+			return s.captureContainerLogs(pod, replicatorSidecar, dateRange, containerDirectoryName, false)
+		})
+	}
+	reportErrors("Collecting replicator sidecar logs", pool.Wait())
+}
+
+// listReplicationGroups returns the DellCSIReplicationGroup objects of the cluster
+func listReplicationGroups() (inventoryObjects, error) {
+	if dynamicClient == nil {
+		return inventoryObjects{}, fmt.Errorf("dynamic client is not configured")
+	}
+	resource := servedResource(replicationGroupResource)
+	inventory := inventoryObjects{kind: "DellCSIReplicationGroup", version: resource.GroupVersion()}
+	replicationGroups, err := dynamicClient.Resource(resource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return inventory, fmt.Errorf("getting replication groups failed with error: %s", err.Error())
+	}
+	for i := range replicationGroups.Items {
+		inventory.objects = append(inventory.objects, &replicationGroups.Items[i])
+	}
+	return inventory, nil
+}
+
+// listReplicationStorageClasses returns the storage classes having replication parameters
+func listReplicationStorageClasses() (inventoryObjects, error) {
+	inventory := inventoryObjects{kind: "StorageClass", version: storagev1.SchemeGroupVersion, describer: &describe.StorageClassDescriber{Interface: clientset}}
+	storageClasses, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return inventory, fmt.Errorf("getting storage classes failed with error: %s", err.Error())
+	}
+	for i := range storageClasses.Items {
+		if len(replicationParameters(&storageClasses.Items[i])) > 0 {
+			inventory.objects = append(inventory.objects, &storageClasses.Items[i])
+		}
+	}
+	return inventory, nil
+}
+
+// replicationParameters returns the sorted replication parameters of the storage class
+func replicationParameters(storageClass *storagev1.StorageClass) []string {
+	var parameters []string
+	for key, value := range storageClass.Parameters {
+		if strings.HasPrefix(key, replicationParameterPrefix) {
+			parameters = append(parameters, key+"="+value)
+		}
+	}
+	sort.Strings(parameters)
+	return parameters
+}
+
+// formatReplicationParameters returns the replication parameters of every storage class
+func formatReplicationParameters(storageClasses []runtime.Object) string {
+	var parameters strings.Builder
+	for _, object := range storageClasses {
+		storageClass := object.(*storagev1.StorageClass)
+		fmt.Fprintf(&parameters, "%s (%s):\n", storageClass.Name, storageClass.Provisioner)
+		for _, parameter := range replicationParameters(storageClass) {
+			fmt.Fprintf(&parameters, "\t%s\n", parameter)
+		}
+	}
+	return parameters.String()
+}
+
+// formatReplicationGroups returns the status of the replication groups as a table followed by their conditions
+func formatReplicationGroups(replicationGroups []runtime.Object) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDRIVER\tSTATE\tLINK STATE\tSOURCE\tREMOTE CLUSTER\tLAST ACTION")
+	for _, object := range replicationGroups {
+		rg := object.(*unstructured.Unstructured).Object
+		driverName, _, _ := unstructured.NestedString(rg, "spec", "driverName")
+		remoteClusterID, _, _ := unstructured.NestedString(rg, "spec", "remoteClusterId")
+		state, _, _ := unstructured.NestedString(rg, "status", "state")
+		linkState, _, _ := unstructured.NestedString(rg, "status", "replicationLinkState", "state")
+		isSource, _, _ := unstructured.NestedBool(rg, "status", "replicationLinkState", "isSource")
+		lastAction, _, _ := unstructured.NestedString(rg, "status", "lastAction", "condition")
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%s\n", object.(*unstructured.Unstructured).GetName(), driverName,
+			state, linkState, isSource, remoteClusterID, lastAction)
+	}
+	w.Flush()
+
+	for _, object := range replicationGroups {
+		conditions, _, _ := unstructured.NestedSlice(object.(*unstructured.Unstructured).Object, "status", "conditions")
+		if len(conditions) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\nConditions of %s:\n", object.(*unstructured.Unstructured).GetName())
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			conditionTime, _, _ := unstructured.NestedString(condition, "time")
+			conditionName, _, _ := unstructured.NestedString(condition, "condition")
+			errorMessage, _, _ := unstructured.NestedString(condition, "errorMessage")
+			fmt.Fprintf(&buf, "\t%s\t%s\t%s\n", conditionTime, conditionName, errorMessage)
+		}
+	}
+	return buf.String()
+}
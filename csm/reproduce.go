@@ -0,0 +1,168 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// reproduceLogLevel is the log level of the driver while the issue is reproduced
+const reproduceLogLevel = "debug"
+
+// logLevelPropagationDelay is the time given to the kubelet to propagate the updated ConfigMap to the
+// driver pods, which reload the log level from the mounted config params
+var logLevelPropagationDelay = time.Minute
+
+// logLevelParam matches the CSI_LOG_LEVEL line of the config params held as YAML in the ConfigMap data
+var logLevelParam = regexp.MustCompile(`(?mi)^([ \t]*CSI_LOG_LEVEL[ \t]*:[ \t]*).*$`)
+
+// logLevelChange holds the config params value of the driver namespace before the log level was raised
+type logLevelChange struct {
+	namespace string
+	configMap string
+	key       string
+	original  string
+}
+
+// Reproduce raises the log level of the drivers to debug, waits for the level to be propagated to the
+// driver pods and for the user to reproduce the issue, and collects the logs created in the meantime.
+// The original log levels are restored once the collection is over, also when it fails or the
+// application exits through a fatal error.
+func Reproduce(targets []CollectionTarget, wait func()) {
+	var changes []logLevelChange
+	var once sync.Once
+	restore := func() {
+		once.Do(func() {
+			for _, change := range changes {
+				if err := change.restore(); err != nil {
+					fmt.Printf("\tRestoring the log level of namespace %s failed, please restore ConfigMap %s manually\n", change.namespace, change.configMap)
+					snsLog.Errorf("Restoring log level of ConfigMap %s/%s failed with error: %s", change.namespace, change.configMap, err.Error())
+					continue
+				}
+				fmt.Printf("\tLog level of namespace %s restored\n", change.namespace)
+			}
+		})
+	}
+	logrus.RegisterExitHandler(restore)
+	defer restore()
+
+	fmt.Println("\n\nRaising the log level of the drivers..........")
+	for _, target := range targets {
+		change, err := raiseLogLevel(target.Namespace)
+		if err != nil {
+			reportErrors("Raising the log level of namespace "+target.Namespace, err)
+			continue
+		}
+		if change == nil {
+			fmt.Printf("\tLog level of namespace %s is already verbose\n", target.Namespace)
+			continue
+		}
+		changes = append(changes, *change)
+		fmt.Printf("\tLog level of namespace %s set to %s\n", target.Namespace, reproduceLogLevel)
+	}
+
+	// the reproduction window starts once the driver pods log at the raised level
+	if len(changes) > 0 && logLevelPropagationDelay > 0 {
+		fmt.Printf("\tWaiting %s for the log level to be propagated to the driver pods..........\n", logLevelPropagationDelay)
+		time.Sleep(logLevelPropagationDelay)
+	}
+	sinceTime := metav1.Now()
+	wait()
+	collectBundle(targets, "true", sinceTime)
+}
+
+// raiseLogLevel sets CSI_LOG_LEVEL of the config params ConfigMap of the namespace to debug,
+// no change is returned when the driver already logs at a verbose level
+func raiseLogLevel(namespace string) (*logLevelChange, error) {
+	configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting configmaps failed with error: %s", err.Error())
+	}
+	for _, configMap := range configMaps.Items {
+		if !strings.HasSuffix(configMap.Name, configParamsSuffix) {
+			continue
+		}
+		if containsAny(strings.ToLower(configParamsLogLevel(configMap)), verboseLogLevels) {
+			return nil, nil
+		}
+		key := configParamsKey(configMap.Data)
+		if key == "" {
+			continue
+		}
+		change := &logLevelChange{namespace: namespace, configMap: configMap.Name, key: key, original: configMap.Data[key]}
+		if err := change.update(setLogLevel(change.original, reproduceLogLevel)); err != nil {
+			return nil, err
+		}
+		return change, nil
+	}
+	return nil, fmt.Errorf("ConfigMap *%s not found", configParamsSuffix)
+}
+
+// configParamsKey returns the data key holding CSI_LOG_LEVEL, or the first key when the parameter is not set
+func configParamsKey(data map[string]string) string {
+	var keys []string
+	for key, value := range data {
+		if logLevelParam.MatchString(value) {
+			return key
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
+
+// setLogLevel returns the config params with CSI_LOG_LEVEL set to the level, the parameter is added when missing
+func setLogLevel(params string, level string) string {
+	if logLevelParam.MatchString(params) {
+		return logLevelParam.ReplaceAllString(params, "${1}"+level)
+	}
+	if params != "" && !strings.HasSuffix(params, "\n") {
+		params += "\n"
+	}
+	return params + "CSI_LOG_LEVEL: " + level + "\n"
+}
+
+// restore writes back the original config params
+func (c logLevelChange) restore() error {
+	return c.update(c.original)
+}
+
+// update writes the config params into the ConfigMap, retrying on conflicting updates
+func (c logLevelChange) update(params string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap, err := clientset.CoreV1().ConfigMaps(c.namespace).Get(context.TODO(), c.configMap, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[c.key] = params
+		_, err = clientset.CoreV1().ConfigMaps(c.namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{})
+		return err
+	})
+}
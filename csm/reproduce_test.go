@@ -0,0 +1,73 @@
+package csm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	type tests = []struct {
+		description string
+		params      string
+		expected    string
+	}
+	var setLogLevelTests = tests{
+		{"log level replaced", "CSI_LOG_LEVEL: \"info\"\nCSI_LOG_FORMAT: \"JSON\"\n", "CSI_LOG_LEVEL: debug\nCSI_LOG_FORMAT: \"JSON\"\n"},
+		{"empty log level replaced", "CSI_LOG_LEVEL:\nCSI_LOG_FORMAT: \"JSON\"\n", "CSI_LOG_LEVEL:debug\nCSI_LOG_FORMAT: \"JSON\"\n"},
+		{"log level added", "CSI_LOG_FORMAT: \"TEXT\"", "CSI_LOG_FORMAT: \"TEXT\"\nCSI_LOG_LEVEL: debug\n"},
+		{"empty params", "", "CSI_LOG_LEVEL: debug\n"},
+	}
+	for _, test := range setLogLevelTests {
+		t.Run(test.description, func(t *testing.T) {
+			if diff := cmp.Diff(setLogLevel(test.params, "debug"), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestReproduce(t *testing.T) {
+	type tests = []struct {
+		description    string
+		params         string
+		reproduceLevel string
+	}
+	var reproduceTests = tests{
+		{"log level raised and restored", "CSI_LOG_LEVEL: \"info\"\nCSI_LOG_FORMAT: \"JSON\"\n", "debug"},
+		{"verbose log level kept", "CSI_LOG_LEVEL: \"trace\"\n", "trace"},
+	}
+	defer func(delay time.Duration) { logLevelPropagationDelay = delay }(logLevelPropagationDelay)
+	logLevelPropagationDelay = 0
+	for _, test := range reproduceTests {
+		t.Run(test.description, func(t *testing.T) {
+			clientset = fake.NewSimpleClientset()
+			_ = CreateNodes(clientset, "10.xx.xxx.xxx")
+			_ = CreateNamespace(clientset, "csi-powerstore")
+			_ = CreatePod(clientset, "csi-powerstore", "pod1", "driver")
+			cm := &v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: "powerstore-config-params", Namespace: "csi-powerstore"},
+				Data: map[string]string{"driver-config-params.yaml": test.params}}
+			_, _ = clientset.CoreV1().ConfigMaps("csi-powerstore").Create(context.TODO(), cm, meta_v1.CreateOptions{})
+
+			logLevel := ""
+			targets := []CollectionTarget{{Driver: PowerStoreStruct{}, Namespace: "csi-powerstore", DriverName: "powerstore"}}
+			Reproduce(targets, func() {
+				cm, _ := clientset.CoreV1().ConfigMaps("csi-powerstore").Get(context.TODO(), "powerstore-config-params", meta_v1.GetOptions{})
+				logLevel = configParamsLogLevel(*cm)
+			})
+
+			if diff := cmp.Diff(logLevel, test.reproduceLevel); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.reproduceLevel, diff)
+			}
+			cm, _ = clientset.CoreV1().ConfigMaps("csi-powerstore").Get(context.TODO(), "powerstore-config-params", meta_v1.GetOptions{})
+			if diff := cmp.Diff(cm.Data["driver-config-params.yaml"], test.params); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.params, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podmonSidecar is the sidecar added to the driver controller and node pods by CSM Resiliency
+const podmonSidecar = "podmon"
+
+// podmonProtectionLabel is the label of the application pods protected by CSM Resiliency
+const podmonProtectionLabel = "podmon.dellemc.com/driver"
+
+// podmonTaint matches the taints added by podmon to the nodes losing the connectivity to the array
+var podmonTaint = regexp.MustCompile(`^offline\..+\.storage\.dell\.com$`)
+
+func init() {
+	RegisterModule(Module{
+		Name:        "resiliency",
+		DisplayName: "Resiliency",
+		Detect: func() []string {
+			return detectContainers(podmonSidecar)
+		},
+		Collect: collectResiliency,
+	})
+}
+
+// collectResiliency collects the node taints added by podmon and the pods protected by podmon,
+// the podmon logs are collected along with the driver pods
+func collectResiliency(namespace string, moduleDirectoryName string, dateRange *metav1.Time) {
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		reportErrors("Getting nodes", err)
+	} else {
+		captureLOG(moduleDirectoryName, "taints.txt", formatPodmonTaints(nodes.Items))
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{LabelSelector: podmonProtectionLabel})
+	if err != nil {
+		reportErrors("Getting pods protected by podmon", err)
+		return
+	}
+	fmt.Printf("\t%d pods protected by podmon\n", len(pods.Items))
+	captureLOG(moduleDirectoryName, "protected-pods.txt", formatProtectedPods(pods.Items))
+}
+
+// formatPodmonTaints returns the podmon taints of the nodes as a table
+func formatPodmonTaints(nodes []corev1.Node) string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tTAINT\tEFFECT\tADDED")
+	for _, node := range nodes {
+		for _, taint := range node.Spec.Taints {
+			if !podmonTaint.MatchString(taint.Key) {
+				continue
+			}
+			added := ""
+			if taint.TimeAdded != nil {
+				added = taint.TimeAdded.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", node.Name, taint.Key, taint.Effect, added)
+		}
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// formatProtectedPods returns the pods protected by podmon along with their node and status as a table
+func formatProtectedPods(pods []corev1.Pod) string {
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tDRIVER\tNODE\tSTATUS")
+	for _, pod := range pods {
+		status := string(pod.Status.Phase)
+		if pod.Status.Reason != "" {
+			status = pod.Status.Reason
+		}
+		if pod.DeletionTimestamp != nil {
+			status = "Terminating"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", pod.Namespace, pod.Name, pod.Labels[podmonProtectionLabel], pod.Spec.NodeName, status)
+	}
+	w.Flush()
+	return buf.String()
+}
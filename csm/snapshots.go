@@ -0,0 +1,163 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// snapshotControllerNamespace is the namespace of the common snapshot controller
+const snapshotControllerNamespace = "kube-system"
+
+// snapshot resources of the external snapshotter and of the Dell volume group snapshotter
+var (
+	volumeSnapshotClassResource        = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotclasses"}
+	volumeSnapshotContentResource      = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotcontents"}
+	volumeSnapshotResource             = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+	dellCsiVolumeGroupSnapshotResource = schema.GroupVersionResource{Group: "volumegroup.storage.dell.com", Version: "v1", Resource: "dellcsivolumegroupsnapshots"}
+)
+
+// collectSnapshots writes the snapshot classes, snapshot contents, snapshots and volume group snapshots
+// of the driver snapshotter under the snapshots directory, along with the snapshots not ready to use
+func (s StorageNameSpaceStruct) collectSnapshots(namespaceDirectoryName string) {
+	fmt.Println("\n\nCollecting snapshots..........")
+	if s.provisioner == "" {
+		fmt.Printf("\tProvisioner of the driver in namespace %s is not found, snapshots will not be collected\n", s.namespaceName)
+		return
+	}
+	if dynamicClient == nil {
+		snsLog.Warnf("Dynamic client is not configured, snapshots of namespace %s will not be collected", s.namespaceName)
+		return
+	}
+
+	// a resource which cannot be listed, e.g. forbidden, is reported and the other resources are still collected
+	var errs []error
+	classes, err := listSnapshotObjects(volumeSnapshotClassResource, "VolumeSnapshotClass", func(object unstructured.Unstructured) bool {
+		driver, _, _ := unstructured.NestedString(object.Object, "driver")
+		return driver == s.provisioner
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	contents, err := listSnapshotObjects(volumeSnapshotContentResource, "VolumeSnapshotContent", func(object unstructured.Unstructured) bool {
+		driver, _, _ := unstructured.NestedString(object.Object, "spec", "driver")
+		return driver == s.provisioner
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// the snapshots belong to the driver through their class or their bound content
+	classNames := make(map[string]bool)
+	for _, object := range classes.objects {
+		classNames[object.(*unstructured.Unstructured).GetName()] = true
+	}
+	contentNames := make(map[string]bool)
+	for _, object := range contents.objects {
+		contentNames[object.(*unstructured.Unstructured).GetName()] = true
+	}
+	snapshots, err := listSnapshotObjects(volumeSnapshotResource, "VolumeSnapshot", func(object unstructured.Unstructured) bool {
+		class, _, _ := unstructured.NestedString(object.Object, "spec", "volumeSnapshotClassName")
+		content, _, _ := unstructured.NestedString(object.Object, "status", "boundVolumeSnapshotContentName")
+		return classNames[class] || contentNames[content]
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	groupSnapshots, err := listSnapshotObjects(dellCsiVolumeGroupSnapshotResource, "DellCsiVolumeGroupSnapshot", func(object unstructured.Unstructured) bool {
+		driver, _, _ := unstructured.NestedString(object.Object, "spec", "driverName")
+		return driver == s.provisioner
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	snapshotsDirectoryName := createDirectory(namespaceDirectoryName + "/snapshots")
+	for _, inventory := range []inventoryObjects{classes, contents, snapshots, groupSnapshots} {
+		if err := s.writeInventory(snapshotsDirectoryName, inventory); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	fmt.Printf("\t%d snapshots, %d volume group snapshots collected\n", len(snapshots.objects), len(groupSnapshots.objects))
+
+	summary, notReady := formatSnapshots(snapshots.objects)
+	captureLOG(snapshotsDirectoryName, "snapshots.txt", summary)
+	if notReady > 0 {
+		fmt.Printf("\tWARNING: %d snapshots are not ready to use, see snapshots/snapshots.txt\n", notReady)
+		snsLog.Warnf("%d snapshots of the driver in namespace %s are not ready to use", notReady, s.namespaceName)
+	}
+	reportErrors("Collecting snapshots of namespace "+s.namespaceName, utilerrors.NewAggregate(errs))
+}
+
+// listSnapshotObjects lists the objects of the snapshot resource selected by the filter,
+// no object is returned when the resource is not installed in the cluster
+func listSnapshotObjects(resource schema.GroupVersionResource, kind string, filter func(unstructured.Unstructured) bool) (inventoryObjects, error) {
+	inventory := inventoryObjects{kind: kind, version: resource.GroupVersion()}
+	list, err := dynamicClient.Resource(resource).List(context.TODO(), metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		snsLog.Infof("Resource %s is not installed in the cluster", resource.String())
+		return inventory, nil
+	}
+	if err != nil {
+		return inventory, fmt.Errorf("getting %s failed with error: %s", resource.Resource, err.Error())
+	}
+	for i := range list.Items {
+		if filter(list.Items[i]) {
+			inventory.objects = append(inventory.objects, &list.Items[i])
+		}
+	}
+	return inventory, nil
+}
+
+// formatSnapshots returns the snapshots as a table followed by the snapshots not ready to use, and their count
+func formatSnapshots(snapshots []runtime.Object) (string, int) {
+	var notReady []string
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tREADYTOUSE\tSOURCEPVC\tSNAPSHOTCLASS\tSNAPSHOTCONTENT")
+	for _, object := range snapshots {
+		snapshot := object.(*unstructured.Unstructured)
+		readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+		source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		class, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+		content, _, _ := unstructured.NestedString(snapshot.Object, "status", "boundVolumeSnapshotContentName")
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\n", snapshot.GetNamespace(), snapshot.GetName(), readyToUse, source, class, content)
+		if !readyToUse {
+			message, _, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message")
+			notReady = append(notReady, fmt.Sprintf("\t%s/%s: %s\n", snapshot.GetNamespace(), snapshot.GetName(), message))
+		}
+	}
+	w.Flush()
+	if len(notReady) > 0 {
+		fmt.Fprintf(&buf, "\nSnapshots not ready to use (readyToUse=false):\n%s", strings.Join(notReady, ""))
+	}
+	return buf.String(), len(notReady)
+}
+
+// collectSnapshotController collects the logs of the common snapshot controller pods into the cluster directory
+func collectSnapshotController(clusterDirectoryName string, dateRange *metav1.Time) {
+	fmt.Println("\n\nCollecting snapshot controller logs..........")
+	collectModulePods(snapshotControllerNamespace, clusterDirectoryName+"/snapshot-controller", dateRange, "snapshot-controller")
+}
@@ -0,0 +1,117 @@
+package csm
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func CreateSnapshotObject(gvr schema.GroupVersionResource, kind string, namespace string, name string, fields map[string]interface{}) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{Object: fields}
+	object.SetAPIVersion(gvr.GroupVersion().String())
+	object.SetKind(kind)
+	object.SetNamespace(namespace)
+	object.SetName(name)
+	return object
+}
+
+func TestCollectSnapshots(t *testing.T) {
+	type tests = []struct {
+		description     string
+		listErrors      map[schema.GroupVersionResource]error
+		expectedFiles   []string
+		unexpectedFiles []string
+		expected        []string
+		unexpected      []string
+	}
+	var collectSnapshotsTests = tests{
+		{"snapshots of the driver collected", nil,
+			[]string{"volumesnapshotclass/powerstore-snapclass.yaml", "volumesnapshotcontent/snapcontent-1.yaml",
+				"volumesnapshot/snapshot-1.yaml", "volumesnapshot/snapshot-2.yaml", "dellcsivolumegroupsnapshot/vgs-1.yaml"},
+			[]string{"volumesnapshotclass/powerflex-snapclass.yaml", "volumesnapshot/snapshot-3.yaml"},
+			[]string{"default    snapshot-1  true        pvc-1", "Snapshots not ready to use (readyToUse=false):",
+				"default/snapshot-2: Failed to create snapshot"},
+			[]string{"snapshot-3"}},
+		{"volume group snapshots not installed",
+			map[schema.GroupVersionResource]error{dellCsiVolumeGroupSnapshotResource: apierrors.NewNotFound(dellCsiVolumeGroupSnapshotResource.GroupResource(), "")},
+			[]string{"volumesnapshot/snapshot-1.yaml"}, []string{"dellcsivolumegroupsnapshot"}, nil, nil},
+		{"snapshot classes forbidden",
+			map[schema.GroupVersionResource]error{volumeSnapshotClassResource: apierrors.NewForbidden(volumeSnapshotClassResource.GroupResource(), "", nil)},
+			[]string{"volumesnapshotcontent/snapcontent-1.yaml", "volumesnapshot/snapshot-1.yaml", "dellcsivolumegroupsnapshot/vgs-1.yaml"},
+			[]string{"volumesnapshotclass"}, []string{"snapshot-1"}, nil},
+	}
+	for _, test := range collectSnapshotsTests {
+		t.Run(test.description, func(t *testing.T) {
+			objects := []runtime.Object{
+				CreateSnapshotObject(volumeSnapshotClassResource, "VolumeSnapshotClass", "", "powerstore-snapclass",
+					map[string]interface{}{"driver": "csi-powerstore.dellemc.com"}),
+				CreateSnapshotObject(volumeSnapshotClassResource, "VolumeSnapshotClass", "", "powerflex-snapclass",
+					map[string]interface{}{"driver": "csi-vxflexos.dellemc.com"}),
+				CreateSnapshotObject(volumeSnapshotContentResource, "VolumeSnapshotContent", "", "snapcontent-1",
+					map[string]interface{}{"spec": map[string]interface{}{"driver": "csi-powerstore.dellemc.com"}}),
+				CreateSnapshotObject(volumeSnapshotResource, "VolumeSnapshot", "default", "snapshot-1", map[string]interface{}{
+					"spec":   map[string]interface{}{"source": map[string]interface{}{"persistentVolumeClaimName": "pvc-1"}},
+					"status": map[string]interface{}{"boundVolumeSnapshotContentName": "snapcontent-1", "readyToUse": true}}),
+				CreateSnapshotObject(volumeSnapshotResource, "VolumeSnapshot", "default", "snapshot-2", map[string]interface{}{
+					"spec":   map[string]interface{}{"volumeSnapshotClassName": "powerstore-snapclass"},
+					"status": map[string]interface{}{"readyToUse": false, "error": map[string]interface{}{"message": "Failed to create snapshot"}}}),
+				CreateSnapshotObject(volumeSnapshotResource, "VolumeSnapshot", "default", "snapshot-3", map[string]interface{}{
+					"spec": map[string]interface{}{"volumeSnapshotClassName": "powerflex-snapclass"}}),
+				CreateSnapshotObject(dellCsiVolumeGroupSnapshotResource, "DellCsiVolumeGroupSnapshot", "default", "vgs-1",
+					map[string]interface{}{"spec": map[string]interface{}{"driverName": "csi-powerstore.dellemc.com"}}),
+			}
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				volumeSnapshotClassResource:        "VolumeSnapshotClassList",
+				volumeSnapshotContentResource:      "VolumeSnapshotContentList",
+				volumeSnapshotResource:             "VolumeSnapshotList",
+				dellCsiVolumeGroupSnapshotResource: "DellCsiVolumeGroupSnapshotList",
+			}, objects...)
+			for resource, err := range test.listErrors {
+				err := err
+				client.PrependReactor("list", resource.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, err
+				})
+			}
+			dynamicClient = client
+			defer func() { dynamicClient = nil }()
+
+			namespaceDirectoryName := createDirectory("snapshot-logs")
+			defer os.RemoveAll(namespaceDirectoryName)
+			s := StorageNameSpaceStruct{namespaceName: "powerstore", provisioner: "csi-powerstore.dellemc.com"}
+			s.collectSnapshots(namespaceDirectoryName)
+
+			for _, file := range test.expectedFiles {
+				if _, err := os.Stat(namespaceDirectoryName + "/snapshots/" + file); err != nil {
+					t.Errorf("file %s not collected: %s", file, err)
+				}
+			}
+			for _, file := range test.unexpectedFiles {
+				if _, err := os.Stat(namespaceDirectoryName + "/snapshots/" + file); err == nil {
+					t.Errorf("file %s not expected", file)
+				}
+			}
+			data, err := ioutil.ReadFile(namespaceDirectoryName + "/snapshots/snapshots.txt")
+			if err != nil {
+				t.Fatalf("snapshots summary not collected: %s", err)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(string(data), expected) {
+					t.Errorf("summary does not contain %q:\n%s", expected, data)
+				}
+			}
+			for _, unexpected := range test.unexpected {
+				if strings.Contains(string(data), unexpected) {
+					t.Errorf("summary contains %q:\n%s", unexpected, data)
+				}
+			}
+		})
+	}
+}
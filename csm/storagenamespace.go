@@ -16,10 +16,8 @@ package csm
 import (
 	"archive/tar"
 	"bufio"
-	"bytes"
 	"context"
 	utils "csm-logcollector/utils"
-	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -27,15 +25,17 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v2"
 
 	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
+	"k8s.io/client-go/rest"
 	describe "k8s.io/kubectl/pkg/describe"
 )
 
@@ -51,22 +51,13 @@ const (
 // Logging object
 var snsLog, logfile = utils.GetLogger()
 
-//GetDriver - Get the CSI driver storage system
-func GetDriver(i int) string {
-	// Declare related array of string to compare each storage system csi driver with an index
-	StorageSystemCSIDriver := [5]string{"POWERSCALE", "UNITY", "POWERSTORE", "POWERMAX", "VXFLEXOS"}
-	driver := ""
-	if i > 0 && i <= 5 {
-		driver = StorageSystemCSIDriver[i-1]
-	}
-	return driver
-}
-
 // StorageNameSpace interface declares log collection methods
 type StorageNameSpace interface {
-	GetLogs(string, string, int, int)
+	CollectionHooks
+	GetLogs(string, string, int, string)
+	CollectLogs(string, string, string, *metav1.Time, string)
 	GetPods() []string
-	GetDriverDetails(string, int) (string, string, string)
+	GetDriverDetails(string, string) (string, string, string)
 	GetLeaseDetails() string
 	GetRunningPods(string, *corev1.Pod, *metav1.Time, string)
 	GetNonRunningPods(string, *corev1.Pod)
@@ -79,53 +70,55 @@ type StorageNameSpaceStruct struct {
 	namespaceName string
 	drivername    string
 	driverversion string
+	driver        Driver
+	leaseHolder   string
+	provisioner   string
+}
+
+// CollectionHooks declares the platform specific steps layered on the shared collection pipeline
+type CollectionHooks interface {
+	// PreCollect is called before the pod logs of the driver namespace are collected
+	PreCollect(StorageNameSpaceStruct, string)
+	// PerPod is called for every pod of the driver namespace with the pod directory
+	PerPod(StorageNameSpaceStruct, *corev1.Pod, string)
+	// PerContainer is called for every container of the pod, including the init and ephemeral containers,
+	// with the container directory, it is run by the worker pool concurrently with the other containers
+	PerContainer(StorageNameSpaceStruct, *corev1.Pod, corev1.Container, string)
+	// PostCollect is called after the pod logs of the driver namespace are collected
+	PostCollect(StorageNameSpaceStruct, string)
 }
 
 var once sync.Once
 var destinationPath string
 var kubeconfigPath string
 var clusterIPAddress string
-var clusterUsername string
-var clusterPassword string
 var clientset kubernetes.Interface
 
+// dynamicClient accesses the custom resources of the CSM modules
+var dynamicClient dynamic.Interface
+
+// restConfig is the configuration of the clients, used to run commands in the pods
+var restConfig *rest.Config
+
 // SetClientSetFromConfig creates ClientSet object
 func SetClientSetFromConfig() kubernetes.Interface {
 	once.Do(func() {
 		if clientset == nil {
-			var kubeconfig *string
 			ReadConfigFile()
-			currentIPAddress, err := utils.GetLocalIP()
-			if err != nil {
-				snsLog.Fatal(err)
-			}
-			snsLog.Infof("Current node IP: %s", currentIPAddress)
-
-			// verify current system IP
-			// container node amd master node are same machine
-			if currentIPAddress == clusterIPAddress {
-				if kubeconfigPath != "" {
-					kubeconfig = flag.String("kubeconfig", kubeconfigPath, "absolute path to the kubeconfig file")
-				} else {
-					home := homedir.HomeDir()
-					kubeconfig = flag.String("kubeconfig", filepath.Join(home, ".kube", "config"), "(optional) absolute path to the kubeconfig file")
-				}
-				// container node amd master node are different machines
-			} else {
-				// SCP config file from remote node to container node
-				remoteKubeconfigPath := utils.ScpConfigFile(kubeconfigPath, clusterIPAddress, clusterUsername, clusterPassword, ".")
-				kubeconfig = flag.String("kubeconfig", remoteKubeconfigPath, "absolute path to the kubeconfig file")
-			}
-			flag.Parse()
-
-			config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+			config, err := buildRestConfig()
 			if err != nil {
+				fmt.Printf("Accessing the cluster failed with error: %s\n", err.Error())
 				snsLog.Fatalf("Error while building config object: %s", err.Error())
 			}
+			restConfig = config
 			clientset, err = kubernetes.NewForConfig(config)
 			if err != nil {
 				snsLog.Fatalf("Error while building clientset object: %s", err.Error())
 			}
+			dynamicClient, err = dynamic.NewForConfig(config)
+			if err != nil {
+				snsLog.Fatalf("Error while building dynamic client object: %s", err.Error())
+			}
 		}
 	})
 	return clientset
@@ -136,12 +129,6 @@ func GetClientSetFromConfig() kubernetes.Interface {
 	return SetClientSetFromConfig()
 }
 
-func init() {
-	if !strings.Contains(os.Args[0], ".test") {
-		clientset = GetClientSetFromConfig()
-	}
-}
-
 // GetNodes returns the array of nodes in the Kubernetes cluster
 func GetNodes() []string {
 	// access the API to list Nodes
@@ -205,7 +192,7 @@ func (s StorageNameSpaceStruct) GetPods() []string {
 }
 
 // GetDriverDetails populates the CSI driver fields
-func (s StorageNameSpaceStruct) GetDriverDetails(namespace string, driverStorageSystem int) (string, string, string) {
+func (s StorageNameSpaceStruct) GetDriverDetails(namespace string, driverStorageSystem string) (string, string, string) {
 	// Get CSI driver info for a particular namespace
 	fmt.Println("\n\nDRIVER INFO..............")
 	fmt.Println("=========================")
@@ -232,14 +219,14 @@ func (s StorageNameSpaceStruct) GetDriverDetails(namespace string, driverStorage
 	s.namespaceName = namespace
 	s.drivername = driverName
 	s.driverversion = driverVersion
-	driverStorage := GetDriver(driverStorageSystem)
-	if strings.Contains(s.drivername, strings.ToLower(driverStorage)) {
+	driver, _ := LookupDriver(driverStorageSystem)
+	if driver.MatchesImage(s.drivername) {
 		fmt.Printf("\tNamespace: \t%s\n", s.namespaceName)
 		fmt.Printf("\tDriver name: \t%s\n", s.drivername)
 		fmt.Printf("\tDriver version: %s\n", s.driverversion)
 		snsLog.Debugf("Driver details listed: %s, %s, %s", s.namespaceName, s.drivername, s.driverversion)
 	} else {
-		fmt.Printf("\nNo CSI Driver for %s storage system found in namespace  %s\n", driverStorage, s.namespaceName)
+		fmt.Printf("\nNo CSI Driver for %s storage system found in namespace  %s\n", driver.DisplayName, s.namespaceName)
 		fmt.Printf("Driver specific logs will not be collected\n")
 	}
 	return namespace, driverName, driverVersion
@@ -256,9 +243,17 @@ func (s StorageNameSpaceStruct) GetLeaseDetails() string {
 		snsLog.Fatalf("Getting lease details in namespace %s failed with error: %s", s.namespaceName, err.Error())
 	}
 	var holder string
-	leasepod := "driver-csi-" + s.namespaceName + "-dellemc-com"
+	var leasepods []string
+	if s.driver.LeaseName != nil {
+		leasepods = append(leasepods, s.driver.LeaseName(s.namespaceName))
+	} else {
+		// driver is not known, look for the leases of all the registered drivers
+		for _, driver := range GetDrivers() {
+			leasepods = append(leasepods, driver.LeaseName(s.namespaceName))
+		}
+	}
 	for _, lease := range leasePodList.Items {
-		if strings.Contains(lease.Name, leasepod) {
+		if containsAny(lease.Name, leasepods) {
 			fmt.Printf("\t%s\n", lease.Name)
 			fmt.Printf("\t%s\n", lease.Namespace)
 			fmt.Printf("\t%s\n", *lease.Spec.HolderIdentity) // Points to same controller pod for all instances
@@ -270,8 +265,219 @@ func (s StorageNameSpaceStruct) GetLeaseDetails() string {
 	return holder
 }
 
+func containsAny(str string, list []string) bool {
+	for _, v := range list {
+		if strings.Contains(str, v) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetLogs accesses the API to get driver/sidecarpod logs of RUNNING pods
-func (s StorageNameSpaceStruct) GetLogs(namespace string, optionalFlag string, daysCount int) {
+func (s StorageNameSpaceStruct) GetLogs(namespace string, optionalFlag string, noOfDays int, driverStorageSystem string) {
+	CollectBundle([]CollectionTarget{{Driver: s, Namespace: namespace, DriverName: driverStorageSystem}}, optionalFlag, noOfDays)
+}
+
+// CollectLogs collects the driver/sidecar pod logs into the namespace directory of the bundle,
+// the platform specific steps are run through the hooks of the registered driver
+func (s StorageNameSpaceStruct) CollectLogs(namespace string, namespaceDirectoryName string, optionalFlag string, dateRange *metav1.Time, driverStorageSystem string) {
+	s.namespaceName, s.drivername, s.driverversion = s.GetDriverDetails(namespace, driverStorageSystem)
+	s.driver, _ = LookupDriver(driverStorageSystem)
+	s.provisioner = getProvisioner(namespace, s.driver)
+	s.collectConfigParams(namespaceDirectoryName)
+	var hooks CollectionHooks = s
+	if s.driver.New != nil {
+		hooks = s.driver.New()
+	}
+	fmt.Println("\n*******************************************************************************")
+
+	//Capturing describe pods
+	podarray := s.GetPods()
+	pool := newWorkerPool()
+	for _, pod := range podarray {
+		pod := pod
+		podDirectoryName := createDirectory(namespaceDirectoryName + "/" + pod)
+		pool.Go(func() error {
+			if err := s.describePod(pod, describe.DescriberSettings{ShowEvents: true}, podDirectoryName); err != nil {
+				return err
+			}
+			if optionalFlag == "True" || optionalFlag == "true" {
+				s.DescribePvcs(pod, describe.DescriberSettings{ShowEvents: true}, podDirectoryName)
+			}
+			return nil
+		})
+	}
+	reportErrors("Describing pods in namespace "+namespace, pool.Wait())
+
+	s.leaseHolder = s.GetLeaseDetails()
+	hooks.PreCollect(s, namespaceDirectoryName)
+
+	// access the API to get driver/sidecarpod logs of RUNNING/NOT RUNNING pods
+	fmt.Println("\n\nCollecting POD logs (driver logs, sidecar logs)..........")
+	podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		snsLog.Fatalf("Getting pods in namespace %s failed with error: %s", namespace, err.Error())
+	}
+	pool = newWorkerPool()
+	for pod := range podList.Items {
+		if podList.Items[pod].Status.Phase == RunningPodState {
+			s.getRunningPods(hooks, pool, namespaceDirectoryName, &podList.Items[pod], dateRange, optionalFlag)
+			snsLog.Infof("Logs collected for running pod %s of %s", podList.Items[pod].Name, namespace)
+		} else {
+			s.getNonRunningPods(hooks, pool, namespaceDirectoryName, &podList.Items[pod], dateRange)
+			snsLog.Infof("Logs collected for non-running pod %s of %s", podList.Items[pod].Name, namespace)
+		}
+		fmt.Println("\t*************************************************************")
+	}
+	reportErrors("Collecting pod logs in namespace "+namespace, pool.Wait())
+
+	s.collectInventory(namespaceDirectoryName)
+	s.collectHelmReleases(namespaceDirectoryName)
+	s.collectEvents(namespaceDirectoryName, dateRange)
+	s.collectSnapshots(namespaceDirectoryName)
+	for _, collector := range s.driver.Collectors {
+		collector(s, namespaceDirectoryName)
+	}
+
+	hooks.PostCollect(s, namespaceDirectoryName)
+}
+
+// PreCollect does nothing by default
+func (s StorageNameSpaceStruct) PreCollect(ns StorageNameSpaceStruct, namespaceDirectoryName string) {
+}
+
+// PerPod does nothing by default
+func (s StorageNameSpaceStruct) PerPod(ns StorageNameSpaceStruct, pod *corev1.Pod, podDirectoryName string) {
+}
+
+// PerContainer does nothing by default
+func (s StorageNameSpaceStruct) PerContainer(ns StorageNameSpaceStruct, pod *corev1.Pod, container corev1.Container, containerDirectoryName string) {
+}
+
+// PostCollect does nothing by default
+func (s StorageNameSpaceStruct) PostCollect(ns StorageNameSpaceStruct, namespaceDirectoryName string) {
+}
+
+// checkSidecars reports whether the registered sidecars of the driver are deployed in the pod,
+// the controller sidecars are checked in the lease holder and the others in the node pods
+func (s StorageNameSpaceStruct) checkSidecars(pod *corev1.Pod) []string {
+	var results []string
+	for _, sidecar := range s.driver.Sidecars {
+		if sidecar.Controller && pod.Name != s.leaseHolder || !sidecar.Controller && !strings.Contains(pod.Name, "node") {
+			continue
+		}
+		result := fmt.Sprintf("%s sidecar is deployed: %t for %s", sidecar.Name, hasContainer(pod, sidecar.Name), pod.Name)
+		snsLog.Infof("%s", result)
+		results = append(results, result)
+	}
+	return results
+}
+
+// hasContainer verifies if the pod runs a container with the given name
+func hasContainer(pod *corev1.Pod, containerName string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == containerName {
+			return true
+		}
+	}
+	return false
+}
+
+// CollectionTarget holds a CSI driver whose logs are collected into the bundle
+type CollectionTarget struct {
+	Driver     StorageNameSpace
+	Namespace  string
+	DriverName string
+}
+
+// collectionTargets are the CSI drivers collected into the current bundle
+var collectionTargets []CollectionTarget
+
+// CollectBundle collects the logs of the given CSI drivers into a single archive
+// with a shared cluster-level section and a subtree per driver namespace
+func CollectBundle(targets []CollectionTarget, optionalFlag string, noOfDays int) {
+	collectBundle(targets, optionalFlag, GetDateRange(noOfDays))
+}
+
+// collectBundle collects the logs of the targets created since the beginning of the date range into a single tarball
+func collectBundle(targets []CollectionTarget, optionalFlag string, dateRange metav1.Time) {
+	fmt.Println("\n*******************************************************************************")
+	var dirName string
+	t := time.Now().Format("20060102150405") //YYYYMMDDhhmmss
+	if len(targets) == 1 {
+		dirName = targets[0].Namespace + "_" + t
+	} else {
+		dirName = "csm-logs_" + t
+	}
+	bundleDirectoryName := createDirectory(dirName)
+
+	// Capturing cluster details once for all the drivers
+	clusterDirectoryName := createDirectory(bundleDirectoryName + "/cluster")
+	DescribeNodes(clusterDirectoryName)
+
+	// the sensitive content is identified upfront for the sanitization filter of the log streams
+	for _, target := range targets {
+		setSensitiveContent(target.Namespace, utils.GetSensitiveContent(clientset, target.Namespace))
+	}
+
+	collectionTargets = targets
+	collectSnapshotController(clusterDirectoryName, &dateRange)
+	collectNodeDiagnostics(clusterDirectoryName, &dateRange)
+	for _, target := range targets {
+		namespaceDirectoryName := createDirectory(targetDirectoryName(bundleDirectoryName, target, targets))
+		target.Driver.CollectLogs(target.Namespace, namespaceDirectoryName, optionalFlag, &dateRange, target.DriverName)
+	}
+
+	collectModules(bundleDirectoryName, &dateRange)
+	writeSummary(bundleDirectoryName)
+
+	// Perform sanitization against the secrets of every driver and module namespace,
+	// the same content masks the compressed logs while they are streamed
+	if ok := utils.SanitizeDirectory(bundleDirectoryName, getAllSensitiveContent()); !ok {
+		snsLog.Warnf("Sanitization not performed for %s.", bundleDirectoryName)
+	}
+
+	errMsg := createTarball(bundleDirectoryName, ".")
+
+	if errMsg != nil {
+		fmt.Printf("Creating tarball %s failed with error: %s\n", bundleDirectoryName, errMsg.Error())
+		snsLog.Fatalf("Creating tarball %s failed with error: %s", bundleDirectoryName, errMsg.Error())
+	}
+}
+
+// targetDirectoryName returns the directory of the target in the bundle, the drivers installed in the same
+// namespace are collected into a subtree per driver so that they do not overwrite each other
+func targetDirectoryName(bundleDirectoryName string, target CollectionTarget, targets []CollectionTarget) string {
+	for _, other := range targets {
+		if other.Namespace == target.Namespace && other.DriverName != target.DriverName {
+			return bundleDirectoryName + "/" + target.Namespace + "/" + target.DriverName
+		}
+	}
+	return bundleDirectoryName + "/" + target.Namespace
+}
+
+// DescribeNodes describes all the nodes of the cluster under the nodes directory
+func DescribeNodes(clusterDirectoryName string) {
+	var s StorageNameSpaceStruct
+	nodes := GetNodes()
+	pool := newWorkerPool()
+	for _, node := range nodes {
+		node := node
+		nodeDirectoryName := createDirectory(clusterDirectoryName + "/nodes/" + node)
+		pool.Go(func() error {
+			return s.describeNode(node, describe.DescriberSettings{ShowEvents: true}, nodeDirectoryName)
+		})
+	}
+	reportErrors("Describing nodes", pool.Wait())
+}
+
+// reportErrors reports the aggregated errors of the worker pool tasks without stopping the collection
+func reportErrors(step string, err error) {
+	if err != nil {
+		fmt.Printf("%s failed with errors: %s\n", step, err.Error())
+		snsLog.Errorf("%s failed with errors: %s", step, err.Error())
+	}
 }
 
 func createDirectory(name string) (dirName string) {
@@ -288,24 +494,38 @@ func createDirectory(name string) (dirName string) {
 
 // DescribeNode - describes the node for a given cluster
 func (s StorageNameSpaceStruct) DescribeNode(nodeName string, describerSettings describe.DescriberSettings, NodeDirectoryName string) {
+	if err := s.describeNode(nodeName, describerSettings, NodeDirectoryName); err != nil {
+		snsLog.Fatalf("%s", err.Error())
+	}
+}
+
+func (s StorageNameSpaceStruct) describeNode(nodeName string, describerSettings describe.DescriberSettings, NodeDirectoryName string) error {
 	d := describe.NodeDescriber{Interface: clientset}
 	DescribeNodeDetails, err := d.Describe(s.namespaceName, nodeName, describerSettings)
 	if err != nil {
-		snsLog.Fatalf("Describing Node %s in namespace %s failed with error: %s", nodeName, s.namespaceName, err.Error())
+		return fmt.Errorf("describing node %s failed with error: %s", nodeName, err.Error())
 	}
 	filename := nodeName + "-describe.txt"
 	captureLOG(NodeDirectoryName, filename, DescribeNodeDetails)
+	return nil
 }
 
 // DescribePods describes the pods in the given namespace
 func (s StorageNameSpaceStruct) DescribePods(podName string, describerSettings describe.DescriberSettings, podDirectoryName string) {
+	if err := s.describePod(podName, describerSettings, podDirectoryName); err != nil {
+		snsLog.Fatalf("%s", err.Error())
+	}
+}
+
+func (s StorageNameSpaceStruct) describePod(podName string, describerSettings describe.DescriberSettings, podDirectoryName string) error {
 	d := describe.PodDescriber{Interface: clientset}
 	DescribePodDetails, err := d.Describe(s.namespaceName, podName, describerSettings)
 	if err != nil {
-		snsLog.Fatalf("Describing pod %s in namespace %s failed with error: %s", podName, s.namespaceName, err.Error())
+		return fmt.Errorf("describing pod %s in namespace %s failed with error: %s", podName, s.namespaceName, err.Error())
 	}
 	filename := podName + "-describe.txt"
 	captureLOG(podDirectoryName, filename, DescribePodDetails)
+	return nil
 }
 
 // DescribePvcs describes the pvcs in the given namespace
@@ -347,71 +567,150 @@ func (s StorageNameSpaceStruct) DescribePvcs(podName string, describerSettings d
 
 // GetRunningPods collects log of the running pod in given namespace
 func (s StorageNameSpaceStruct) GetRunningPods(namespaceDirectoryName string, pod *corev1.Pod, dateRange *metav1.Time, optionalFlag string) {
+	pool := newWorkerPool()
+	s.getRunningPods(s, pool, namespaceDirectoryName, pod, dateRange, optionalFlag)
+	reportErrors("Collecting logs of pod "+pod.Name, pool.Wait())
+}
+
+// getRunningPods submits the log streams of the pod containers to the worker pool
+func (s StorageNameSpaceStruct) getRunningPods(hooks CollectionHooks, pool *workerPool, namespaceDirectoryName string, pod *corev1.Pod, dateRange *metav1.Time, optionalFlag string) {
 	var dirName string
 	fmt.Printf("pod.Name........%s\n", pod.Name)
 	fmt.Printf("pod.Status.Phase.......%s\n", pod.Status.Phase)
 	dirName = namespaceDirectoryName + "/" + pod.Name
 	podDirectoryName := createDirectory(dirName)
+	s.checkSidecars(pod)
+	hooks.PerPod(s, pod, podDirectoryName)
 
-	if optionalFlag == "False" || optionalFlag == "false" {
+	optional := optionalFlag != "False" && optionalFlag != "false"
+	if !optional {
 		str := "Pod " + pod.Name + " is in running state\n"
 		filename := pod.Name + ".txt"
 		captureLOG(podDirectoryName, filename, str)
 		fmt.Println()
-	} else {
-		for container := range pod.Spec.Containers {
-			fmt.Printf("\t Collecting Logs from container %s\n", pod.Spec.Containers[container].Name)
-			dirName = podDirectoryName + "/" + pod.Spec.Containers[container].Name
-			containerDirectoryName := createDirectory(dirName)
-
-			opts := corev1.PodLogOptions{}
-			opts.Container = pod.Spec.Containers[container].Name
-			if dateRange != nil {
-				fmt.Printf("Logs will be collected from: %v \n", dateRange)
-				opts.SinceTime = dateRange
+	}
+	if dateRange != nil && (optional || hasContainer(pod, podmonSidecar)) {
+		fmt.Printf("Logs will be collected from: %v \n", dateRange)
+	}
+	for _, container := range podContainers(pod) {
+		container := container
+		// the CSM Resiliency podmon logs explain the pod evictions, they are collected in any case
+		collectLogs := optional || container.Name == podmonSidecar
+		// the restart history and the previous instance of the crashed containers are collected in any case
+		restarted := getRestartCount(pod, container.Name) > 0
+		containerDirectoryName := createDirectory(podDirectoryName + "/" + container.Name)
+		writeContainerSummary(pod, container.Name, containerDirectoryName)
+		if !collectLogs && !restarted {
+			continue
+		}
+		fmt.Printf("\t Collecting Logs from container %s\n", container.Name)
+		pool.Go(func() error {
+			// the current and previous logs are captured independently, a failure of one does not lose the other
+			var errs []error
+			if collectLogs {
+				if err := s.captureContainerLogs(pod, container.Name, dateRange, containerDirectoryName, false); err != nil {
+					errs = append(errs, err)
+				}
 			}
-			req := clientset.CoreV1().Pods(s.namespaceName).GetLogs(pod.Name, &opts)
-			podLogs, err := req.Stream(context.TODO())
-			if err != nil {
-				snsLog.Errorf("Opening stream for pod %s in namespace %s failed with error: %s", pod.Name, pod.Namespace, err.Error())
+			// the previous instance holds the logs of the crash for the restarted containers
+			if restarted {
+				if err := s.captureContainerLogs(pod, container.Name, dateRange, containerDirectoryName, true); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if collectLogs {
+				hooks.PerContainer(s, pod, container, containerDirectoryName)
 			}
+			return utilerrors.NewAggregate(errs)
+		})
+	}
+}
 
-			defer func() {
-				if err := podLogs.Close(); err != nil {
-					snsLog.Fatalf("Error streaming file with error %s \n", err.Error())
-				}
-			}()
+// captureContainerLogs writes the logs of the pod container into the container directory,
+// the logs of the previous instance of the container are written when previous is set
+func (s StorageNameSpaceStruct) captureContainerLogs(pod *corev1.Pod, containerName string, dateRange *metav1.Time, containerDirectoryName string, previous bool) error {
+	opts := corev1.PodLogOptions{}
+	opts.Container = containerName
+	opts.Previous = previous
+	if dateRange != nil {
+		opts.SinceTime = dateRange
+	}
+	req := clientset.CoreV1().Pods(s.namespaceName).GetLogs(pod.Name, &opts)
+	podLogs, err := req.Stream(context.TODO())
+	if err != nil {
+		return fmt.Errorf("opening stream for container %s of pod %s in namespace %s failed with error: %s", containerName, pod.Name, pod.Namespace, err.Error())
+	}
 
-			buf := new(bytes.Buffer)
-			_, err = io.Copy(buf, podLogs)
-			if err != nil {
-				snsLog.Errorf("Error in copy information from podLogs to buf: %s", err.Error())
-			}
-			str := buf.String()
+	filename := pod.Name + "-" + containerName + ".txt"
+	if previous {
+		filename = pod.Name + "-" + containerName + "-previous.txt"
+	}
+	f, err := createLogFile(containerDirectoryName, filename)
+	if err != nil {
+		podLogs.Close()
+		return fmt.Errorf("creating log file %s failed with error: %s", filename, err.Error())
+	}
 
-			filename := pod.Name + "-" + pod.Spec.Containers[container].Name + ".txt"
-			captureLOG(containerDirectoryName, filename, str)
-		}
+	// the logs are streamed to the disk, the stream and the file are closed as soon as the copy ends
+	_, err = io.Copy(f, podLogs)
+	if closeErr := podLogs.Close(); closeErr != nil {
+		snsLog.Errorf("Closing stream for container %s of pod %s failed with error: %s", containerName, pod.Name, closeErr.Error())
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing logs of container %s of pod %s failed with error: %s", containerName, pod.Name, err.Error())
 	}
+	return nil
 }
 
 // GetNonRunningPods collects log of the nonrunning pod in given namespace
 func (s StorageNameSpaceStruct) GetNonRunningPods(namespaceDirectoryName string, pod *corev1.Pod) {
+	pool := newWorkerPool()
+	s.getNonRunningPods(s, pool, namespaceDirectoryName, pod, nil)
+	reportErrors("Collecting logs of pod "+pod.Name, pool.Wait())
+}
+
+// getNonRunningPods records the status of the pod containers and submits the log streams
+// which are still served by the API, e.g. of the crashed or completed containers
+func (s StorageNameSpaceStruct) getNonRunningPods(hooks CollectionHooks, pool *workerPool, namespaceDirectoryName string, pod *corev1.Pod, dateRange *metav1.Time) {
 	var dirName string
 	fmt.Printf("pod.Name........%s\n", pod.Name)
 	fmt.Printf("pod.Status.Phase.......%s\n", pod.Status.Phase)
-	containerCount := len(pod.Spec.Containers)
-	fmt.Printf("There are %d containers for the pod\n", containerCount)
+	containers := podContainers(pod)
+	fmt.Printf("There are %d containers for the pod\n", len(containers))
 	dirName = namespaceDirectoryName + "/" + pod.Name
 	podDirectoryName := createDirectory(dirName)
+	s.checkSidecars(pod)
+	hooks.PerPod(s, pod, podDirectoryName)
 
-	for container := range pod.Spec.Containers {
-		fmt.Println("\t", pod.Spec.Containers[container].Name)
-		dirName = podDirectoryName + "/" + pod.Spec.Containers[container].Name
+	podStatus := string(pod.Status.Phase)
+	if podStatus == "" {
+		podStatus = "not running"
+	}
+	for _, container := range containers {
+		container := container
+		fmt.Println("\t", container.Name)
+		dirName = podDirectoryName + "/" + container.Name
 		containerDirectoryName := createDirectory(dirName)
-		var str string = "Pod status: not running"
+		var str string = "Pod status: " + podStatus
 		filename := pod.Name + ".txt"
 		captureLOG(containerDirectoryName, filename, str)
+		writeContainerSummary(pod, container.Name, containerDirectoryName)
+		pool.Go(func() error {
+			// the logs are not available for the containers which never started
+			if err := s.captureContainerLogs(pod, container.Name, dateRange, containerDirectoryName, false); err != nil {
+				snsLog.Infof("Logs not available for container %s of pod %s: %s", container.Name, pod.Name, err.Error())
+			}
+			if getRestartCount(pod, container.Name) > 0 {
+				if err := s.captureContainerLogs(pod, container.Name, dateRange, containerDirectoryName, true); err != nil {
+					snsLog.Infof("Previous logs not available for container %s of pod %s: %s", container.Name, pod.Name, err.Error())
+				}
+			}
+			hooks.PerContainer(s, pod, container, containerDirectoryName)
+			return nil
+		})
 		fmt.Println()
 	}
 }
@@ -507,6 +806,10 @@ func ReadConfigFile() {
 				}
 
 				for key, value := range kubeconfigDetails {
+					// the SSH details are read by utils.GetRemoteSSHConfig
+					if key != "path" && key != "ip_address" {
+						continue
+					}
 					// type assertion from interface{} type to string type
 					key, ok1 := key.(string)
 					value, ok2 := value.(string)
@@ -521,12 +824,6 @@ func ReadConfigFile() {
 						if key == "ip_address" {
 							clusterIPAddress = value
 						}
-						if key == "username" {
-							clusterUsername = value
-						}
-						if key == "password" {
-							clusterPassword = value
-						}
 					} else {
 						snsLog.Infof("No value found for kubeconfig_details sub-key: %s", key)
 					}
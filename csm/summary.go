@@ -0,0 +1,58 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// bundleSummary holds the findings of the collection per driver namespace, they are shown
+// to the user at the end of the collection and written to the summary file of the bundle
+var bundleSummary = struct {
+	sync.Mutex
+	namespaces map[string][]string
+}{namespaces: make(map[string][]string)}
+
+// addSummary records a finding of the collection of the namespace
+func addSummary(namespace string, format string, args ...interface{}) {
+	bundleSummary.Lock()
+	defer bundleSummary.Unlock()
+	bundleSummary.namespaces[namespace] = append(bundleSummary.namespaces[namespace], fmt.Sprintf(format, args...))
+}
+
+// writeSummary prints the findings of the collection and writes them into summary.txt of the bundle
+func writeSummary(bundleDirectoryName string) {
+	bundleSummary.Lock()
+	defer bundleSummary.Unlock()
+	var namespaces []string
+	for namespace := range bundleSummary.namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var summary strings.Builder
+	summary.WriteString("Collection summary\n==================\n")
+	for _, namespace := range namespaces {
+		fmt.Fprintf(&summary, "%s:\n", namespace)
+		for _, finding := range bundleSummary.namespaces[namespace] {
+			fmt.Fprintf(&summary, "\t%s\n", finding)
+		}
+	}
+	fmt.Printf("\n%s", summary.String())
+	captureLOG(bundleDirectoryName, "summary.txt", summary.String())
+	bundleSummary.namespaces = make(map[string][]string)
+}
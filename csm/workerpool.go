@@ -0,0 +1,73 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package csm
+
+import (
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DefaultParallelism is the number of workers used when no parallelism is configured
+const DefaultParallelism = 4
+
+var parallelism = DefaultParallelism
+
+// SetParallelism sets the number of workers for node describes, pod describes and log streams
+func SetParallelism(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	parallelism = workers
+}
+
+// workerPool runs the collection tasks with a bounded number of workers
+// and reports the task errors in the order the tasks were submitted
+type workerPool struct {
+	workers chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+	errs    []error
+}
+
+func newWorkerPool() *workerPool {
+	return &workerPool{workers: make(chan struct{}, parallelism)}
+}
+
+// Go submits the task to the pool, it blocks while all the workers are busy
+func (w *workerPool) Go(task func() error) {
+	w.mutex.Lock()
+	index := len(w.errs)
+	w.errs = append(w.errs, nil)
+	w.mutex.Unlock()
+
+	w.workers <- struct{}{}
+	w.wg.Add(1)
+	go func() {
+		defer func() {
+			<-w.workers
+			w.wg.Done()
+		}()
+		err := task()
+		w.mutex.Lock()
+		w.errs[index] = err
+		w.mutex.Unlock()
+	}()
+}
+
+// Wait waits for the submitted tasks and returns their aggregated errors
+func (w *workerPool) Wait() error {
+	w.wg.Wait()
+	return utilerrors.NewAggregate(w.errs)
+}
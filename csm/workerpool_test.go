@@ -0,0 +1,67 @@
+package csm
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWorkerPool(t *testing.T) {
+	type tests = []struct {
+		description    string
+		parallelism    int
+		tasks          int
+		failingTasks   []int
+		expectedErrors string
+	}
+	var workerPoolTests = tests{
+		{"tasks without errors", 4, 20, nil, ""},
+		{"errors reported in submission order", 3, 10, []int{7, 2}, "[task 2 failed, task 7 failed]"},
+		{"single worker", 1, 5, []int{0}, "task 0 failed"},
+	}
+	defer SetParallelism(DefaultParallelism)
+
+	for _, test := range workerPoolTests {
+		t.Run(test.description, func(t *testing.T) {
+			SetParallelism(test.parallelism)
+			failing := make(map[int]bool)
+			for _, task := range test.failingTasks {
+				failing[task] = true
+			}
+			var running, maxRunning int32
+			pool := newWorkerPool()
+			for task := 0; task < test.tasks; task++ {
+				task := task
+				pool.Go(func() error {
+					current := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						max := atomic.LoadInt32(&maxRunning)
+						if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					if failing[task] {
+						return fmt.Errorf("task %d failed", task)
+					}
+					return nil
+				})
+			}
+			err := pool.Wait()
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if diff := cmp.Diff(got, test.expectedErrors); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedErrors, diff)
+			}
+			if maxRunning > int32(test.parallelism) {
+				t.Errorf("%d tasks run concurrently, parallelism is %d", maxRunning, test.parallelism)
+			}
+		})
+	}
+}
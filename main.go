@@ -16,159 +16,579 @@ package main
 import (
 	"csm-logcollector/csm"
 	utils "csm-logcollector/utils"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 var logger, _ = utils.GetLogger()
 var version = "development"
 
+const consentMsg string = "As a part of log collection, logs will be sent for further analysis. Please provide your consent.(Y/y)"
+
+// maximum number of days for which the logs can be collected
+const maxNoOfDays = 180
+
 func main() {
 	logger.Info("Log started for csm-logcollector")
+	handleInterrupt()
+
+	command := "collect"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command = args[0]
+		args = args[1:]
+	}
+
+	switch command {
+	case "collect":
+		runCollect(args)
+	case "reproduce":
+		runReproduce(args)
+	case "list-namespaces":
+		runListNamespaces(args)
+	case "list-drivers":
+		runListDrivers(args)
+	case "discover":
+		runDiscover(args)
+	case "version":
+		fmt.Printf("CSM Log Collector, version: %s\n", version)
+	case "help":
+		usage()
+	default:
+		fmt.Printf("Unknown command: %s\n\n", command)
+		usage()
+		logger.Fatalf("Unknown command: %s", command)
+	}
+}
+
+// handleInterrupt exits through the logger on an interrupt, so that the exit handlers restore
+// the cluster changes made for the collection like the debug pods and the driver log level
+func handleInterrupt() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		fmt.Printf("\nInterrupted, cleaning up...\n")
+		logger.Fatalf("Exiting the application on signal %s", sig)
+	}()
+}
+
+func usage() {
+	fmt.Println("Usage: csm-logcollector [command] [flags]")
+	fmt.Println("\nCommands:")
+	fmt.Println("  collect          Collect the CSI driver logs (default command)")
+	fmt.Println("  reproduce        Raise the driver log level to debug while the issue is reproduced and collect the logs")
+	fmt.Println("  list-namespaces  List the namespaces in the cluster")
+	fmt.Println("  list-drivers     List the supported CSI drivers")
+	fmt.Println("  discover         List the Dell CSI drivers installed in the cluster")
+	fmt.Println("  version          Print the application version")
+	fmt.Println("\nRun 'csm-logcollector <command> -h' for the flags of a command.")
+}
+
+// connectionOptions holds the flags of the access to the cluster
+type connectionOptions struct {
+	kubeconfig      string
+	context         string
+	remote          bool
+	insecureHostKey bool
+}
+
+// addConnectionFlags registers the flags of the access to the cluster
+func addConnectionFlags(fs *flag.FlagSet, opts *connectionOptions) {
+	fs.StringVar(&opts.kubeconfig, "kubeconfig", "", "kubeconfig file of the cluster (default KUBECONFIG, the path of kubeconfig_details or ~/.kube/config)")
+	fs.StringVar(&opts.context, "context", "", "kubeconfig context of the cluster (default the current context)")
+	fs.BoolVar(&opts.remote, "remote-kubeconfig", false, "copy the kubeconfig and the driver secret files from the cluster over SSH using kubeconfig_details of config.yml")
+	fs.BoolVar(&opts.insecureHostKey, "insecure-skip-host-key-verification", false,
+		"accept any SSH host key of the remote cluster and nodes without verification (not recommended)")
+}
+
+// connect configures the access to the cluster and builds the clients
+func connect(opts connectionOptions) {
+	if opts.insecureHostKey {
+		fmt.Println("WARNING: SSH host key verification is disabled")
+		logger.Warn("SSH host key verification is disabled by --insecure-skip-host-key-verification")
+	}
+	if opts.remote && opts.kubeconfig != "" {
+		fmt.Println("Please provide either --kubeconfig or --remote-kubeconfig")
+		logger.Fatalf("Both --kubeconfig and --remote-kubeconfig are provided")
+	}
+	utils.SetInsecureIgnoreHostKey(opts.insecureHostKey)
+	csm.SetClusterAccess(csm.ClusterAccess{Kubeconfig: opts.kubeconfig, Context: opts.context, Remote: opts.remote})
+	csm.GetClientSetFromConfig()
+}
+
+// collectOptions holds the user input required for log collection
+type collectOptions struct {
+	connectionOptions
+	consent     bool
+	all         bool
+	drivers     stringList
+	namespaces  stringList
+	optional    bool
+	noOfDays    int
+	parallelism int
+	compress    bool
+	modules     string
+	metrics     bool
+	nodes       bool
+	debugPods   bool
+	debugImage  string
+	nodeLabels  string
+	target      string
+	sanitize    bool
+	interactive bool
+	optionalSet bool
+	noOfDaysSet bool
+	window      time.Duration
+}
+
+func runCollect(args []string) {
+	var opts collectOptions
+	fs := newCollectFlagSet("collect", &opts)
+	fs.BoolVar(&opts.optional, "optional", false, "collect the optional logs (pvc describe and container logs)")
+	fs.IntVar(&opts.noOfDays, "days", 0, "number of days the logs need to be collected from today, 1 to 180 (0 skips this filter)")
+	_ = fs.Parse(args)
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "optional":
+			opts.optionalSet = true
+		case "days":
+			opts.noOfDaysSet = true
+		}
+	})
+	setCollectOptions(&opts)
+
+	targets := getTargets(&opts)
+	getOptionalFlag(&opts)
+	getNoOfDays(&opts)
+	csm.CollectBundle(targets, strconv.FormatBool(opts.optional), opts.noOfDays)
+}
+
+func runReproduce(args []string) {
+	var opts collectOptions
+	fs := newCollectFlagSet("reproduce", &opts)
+	fs.DurationVar(&opts.window, "window", 0, "time given to reproduce the issue, e.g. 10m (0 waits until Enter is pressed)")
+	_ = fs.Parse(args)
+	setCollectOptions(&opts)
+
+	if opts.window < 0 || (opts.window == 0 && !opts.interactive) {
+		fmt.Println("Please provide the time given to reproduce the issue using --window")
+		logger.Fatalf("Invalid reproduction window: %s", opts.window)
+	}
+	targets := getTargets(&opts)
+	csm.Reproduce(targets, func() { waitForReproduction(opts.window) })
+}
+
+// newCollectFlagSet returns the flags shared by the commands collecting a bundle
+func newCollectFlagSet(name string, opts *collectOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Var(&opts.drivers, "driver", "CSI driver for which the logs need to be collected (see list-drivers), repeated or as driver:namespace for several drivers")
+	fs.Var(&opts.namespaces, "namespace", "namespace in which the CSI driver is installed, repeated in the order of --driver")
+	fs.BoolVar(&opts.consent, "yes", false, "provide the consent for log collection without prompting")
+	fs.BoolVar(&opts.all, "all", false, "collect the logs of all the CSI drivers discovered in the cluster")
+	fs.BoolVar(&opts.compress, "compress", false, "write the container logs gzip compressed, the logs are sanitized while they are streamed")
+	fs.BoolVar(&opts.sanitize, "sanitize-logs", false, "mask the sensitive content of the driver secrets while the container logs are streamed")
+	fs.StringVar(&opts.modules, "modules", "all", "comma separated CSM modules collected when installed, 'all' or 'none' (see list-drivers)")
+	fs.BoolVar(&opts.metrics, "scrape-metrics", false, "scrape the /metrics endpoints of the CSM Observability services through the API server proxy")
+	fs.BoolVar(&opts.nodes, "node-diagnostics", false, "collect the host diagnostics of the nodes over SSH with the node groups of config.yml")
+	fs.BoolVar(&opts.debugPods, "node-debug-pods", false, "collect the host diagnostics of the nodes in privileged debug pods instead of SSH")
+	fs.StringVar(&opts.debugImage, "node-debug-image", csm.DefaultDebugImage, "image of the node debug pods, it needs chroot and sh")
+	fs.StringVar(&opts.nodeLabels, "node-selector", "", "label selector of the nodes whose host diagnostics are collected")
+	fs.StringVar(&opts.target, "replication-target-kubeconfig", "", "kubeconfig of the replication target cluster whose CSM Replication is collected too")
+	fs.IntVar(&opts.parallelism, "parallelism", csm.DefaultParallelism, "number of node describes, pod describes and log streams collected concurrently")
+	addConnectionFlags(fs, &opts.connectionOptions)
+	return fs
+}
+
+// setCollectOptions validates the parsed flags and configures the collection accordingly
+func setCollectOptions(opts *collectOptions) {
+	opts.interactive = isInteractive()
+	if err := CheckParallelism(opts.parallelism); err != nil {
+		fmt.Println("Invalid parallelism, please enter a number greater than 0.")
+		logger.Fatalf("Invalid parallelism: %s", err.Error())
+	}
+	csm.SetParallelism(opts.parallelism)
+	csm.SetLogFilters(csm.LogFilters{Compress: opts.compress, Sanitize: opts.sanitize})
+	modules, err := ParseModules(opts.modules)
+	if err != nil {
+		fmt.Printf("Invalid modules: %s\n", err.Error())
+		logger.Fatalf("Invalid modules: %s", err.Error())
+	}
+	csm.SetModules(modules)
+	csm.SetScrapeMetrics(opts.metrics)
+	csm.SetReplicationTarget(opts.target)
+	csm.SetNodeDiagnostics(csm.NodeDiagnostics{Enabled: opts.nodes || opts.debugPods, DebugPods: opts.debugPods,
+		DebugImage: opts.debugImage, NodeSelector: opts.nodeLabels})
+
 	fmt.Printf("\n\n\tCSM Log Collector, version: %s\n", version)
 	fmt.Println("\t=================================")
 	fmt.Println()
-	var consent string
-	var namespace string
-	var optionalFlag string
-	var result bool
-	var nsSlice []string
-	var p csm.StorageNameSpace
-	var err error
-	var ipCount int
-	const consentMsg string = "As a part of log collection, logs will be sent for further analysis. Please provide your consent.(Y/y)"
 
-	fmt.Println(consentMsg)
-	ipCount, err = fmt.Scanln(&consent)
-	if (err != nil || consent != "Y" && consent != "y") || ipCount == 0 {
+	getConsent(opts)
+	connect(opts.connectionOptions)
+}
+
+// stringList is a flag which can be repeated, e.g. --driver powerstore --driver powerscale
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// driverNamespace is a CSI driver and the namespace in which it is installed, as given by the user
+type driverNamespace struct {
+	driver    string
+	namespace string
+}
+
+// getTargets returns the CSI drivers to be collected, either the discovered ones selected by the user
+// or the driver and namespace pairs given through the flags or the prompts
+func getTargets(opts *collectOptions) []csm.CollectionTarget {
+	var targets []csm.CollectionTarget
+	pairs, err := PairDriverNamespaces(opts.drivers, opts.namespaces)
+	if err != nil {
+		fmt.Printf("Invalid CSI drivers and namespaces: %s\n", err.Error())
+		logger.Fatalf("Invalid CSI drivers and namespaces: %s", err.Error())
+	}
+	if len(pairs) == 0 {
+		for _, install := range getDriverInstalls(opts) {
+			driver, _ := csm.LookupDriver(install.Driver)
+			targets = append(targets, csm.CollectionTarget{Driver: driver.New(), Namespace: install.Namespace, DriverName: driver.Name})
+		}
+		if len(targets) > 0 {
+			return targets
+		}
+		// the interactive users give the drivers one after the other
+		for len(pairs) == 0 || promptAnotherDriver() {
+			pairs = append(pairs, driverNamespace{})
+			pair := &pairs[len(pairs)-1]
+			pair.driver = getDriverChoice(opts, pair.driver)
+			pair.namespace = getNamespace(opts, pair.namespace)
+		}
+	} else {
+		for i := range pairs {
+			pairs[i].driver = getDriverChoice(opts, pairs[i].driver)
+			pairs[i].namespace = getNamespace(opts, pairs[i].namespace)
+		}
+	}
+
+	selected := make(map[driverNamespace]bool)
+	for _, pair := range pairs {
+		if selected[pair] {
+			continue
+		}
+		selected[pair] = true
+		driver, _ := csm.LookupDriver(pair.driver)
+		targets = append(targets, csm.CollectionTarget{Driver: driver.New(), Namespace: pair.namespace, DriverName: driver.Name})
+	}
+	return targets
+}
+
+// getDriverInstalls returns all the discovered CSI drivers with --all, otherwise those selected by the user,
+// the interactive users select the driver and namespace when the discovery is forbidden
+func getDriverInstalls(opts *collectOptions) []csm.DriverInstall {
+	if !opts.all && !opts.interactive {
+		fmt.Println("Please provide the CSI driver and namespace using --driver and --namespace, or pass --all")
+		logger.Fatalf("CSI Driver and namespace are not provided")
+	}
+	discoveredInstalls, err := csm.DiscoverDrivers()
+	if err != nil {
+		if opts.interactive && !opts.all && apierrors.IsForbidden(err) {
+			fmt.Println("\tDiscovery of the CSI drivers is not permitted, please select the CSI driver and namespace")
+			logger.Warnf("Discovering CSI drivers failed with error: %s", err.Error())
+			return nil
+		}
+		fmt.Printf("Discovering CSI drivers failed with error: %s\n", err.Error())
+		logger.Fatalf("Discovering CSI drivers failed with error: %s", err.Error())
+	}
+	var driverInstalls []csm.DriverInstall
+	for _, install := range discoveredInstalls {
+		if install.Namespace != "" {
+			driverInstalls = append(driverInstalls, install)
+		}
+	}
+	csm.PrintDriverInstalls(driverInstalls)
+	if len(driverInstalls) == 0 {
+		if opts.all {
+			fmt.Println("No Dell CSI driver found in the cluster")
+			logger.Fatalf("No Dell CSI driver found in the cluster")
+		}
+		return nil
+	}
+	if opts.all {
+		return driverInstalls
+	}
+	var selectedInstalls []csm.DriverInstall
+	for _, i := range promptDriverInstalls(len(driverInstalls)) {
+		selectedInstalls = append(selectedInstalls, driverInstalls[i])
+	}
+	return selectedInstalls
+}
+
+func runListNamespaces(args []string) {
+	var opts connectionOptions
+	fs := flag.NewFlagSet("list-namespaces", flag.ExitOnError)
+	addConnectionFlags(fs, &opts)
+	_ = fs.Parse(args)
+	connect(opts)
+	csm.GetNamespaces()
+}
+
+func runDiscover(args []string) {
+	var opts connectionOptions
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	addConnectionFlags(fs, &opts)
+	_ = fs.Parse(args)
+	connect(opts)
+	driverInstalls, err := csm.DiscoverDrivers()
+	if err != nil {
+		fmt.Printf("Discovering CSI drivers failed with error: %s\n", err.Error())
+		logger.Fatalf("Discovering CSI drivers failed with error: %s", err.Error())
+	}
+	csm.PrintDriverInstalls(driverInstalls)
+}
+
+func runListDrivers(args []string) {
+	fs := flag.NewFlagSet("list-drivers", flag.ExitOnError)
+	_ = fs.Parse(args)
+	fmt.Println("Supported CSI drivers:")
+	for i, driver := range csm.GetDrivers() {
+		names := append([]string{driver.Name}, driver.Aliases...)
+		fmt.Printf("%d: %s (--driver %s)\n", i+1, driver.DisplayName, strings.Join(names, " | "))
+	}
+	fmt.Println("\nSupported CSM modules:")
+	for i, module := range csm.GetModules() {
+		fmt.Printf("%d: %s (--modules %s)\n", i+1, module.DisplayName, module.Name)
+	}
+}
+
+// getConsent verifies that the consent is given either through the flag or the prompt
+func getConsent(opts *collectOptions) {
+	if opts.consent {
+		return
+	}
+	if !opts.interactive {
+		fmt.Println("\nExiting the application as the user consent is not granted, please pass --yes to provide the consent")
+		logger.Fatalf("Exiting the application as consent is not provided.")
+	}
+	opts.consent = promptConsent()
+	if !opts.consent {
 		fmt.Println("\nExiting the application as the user consent is not granted or invalid input")
 		logger.Fatalf("Exiting the application as consent is not provided or invalid input.")
 	}
+}
 
-	driveOption := ""
-	fmt.Println("Please select the respective storage array for which CSI Driver logs need to be collected:")
-	fmt.Println("1: PowerScale/Isilon\n2: Unity\n3: PowerStore\n4: PowerMax\n5: PowerFlex/VxFlexOS")
-	fmt.Println("\nPlease enter your choice (e.g. enter '1' for PowerScale) :")
-	ipCount, err = fmt.Scanln(&driveOption)
-	driveChoice, err := strconv.Atoi(driveOption)
-	if err != nil || driveChoice < 1 || driveChoice > 5 || ipCount <= 0 {
+// getDriverChoice validates the driver given through the flag or the prompt
+func getDriverChoice(opts *collectOptions, driver string) string {
+	if driver == "" {
+		if !opts.interactive {
+			fmt.Println("Please provide the CSI driver using --driver")
+			logger.Fatalf("CSI Driver is not provided")
+		}
+		driver = promptDriver()
+	}
+	driver, err := ParseDriverChoice(driver)
+	if err != nil {
 		fmt.Println("Invalid choice, please enter correct choice")
-		logger.Fatalf("Entering CSI Driver choice failed")
+		logger.Fatalf("Entering CSI Driver choice failed: %s", err.Error())
 	}
+	return driver
+}
 
-	fmt.Println("\nEnter the namespace: ")
-	_, errns := fmt.Scanln(&namespace)
-	if errns != nil {
-		fmt.Printf("\nEntering namespace failed with error %s \n", errns.Error())
-		logger.Fatalf("Entering namespace failed with error: %s", errns.Error())
+// getNamespace validates the namespace given through the flag or the prompt
+func getNamespace(opts *collectOptions, namespace string) string {
+	if namespace == "" && !opts.interactive {
+		fmt.Println("Please provide the namespace using --namespace")
+		logger.Fatalf("Namespace is not provided")
 	}
-	temp := strings.ToLower(namespace)
 	namespaces := csm.GetNamespaces()
+	if namespace == "" {
+		return promptNamespace(namespaces)
+	}
 
-	result, nsSlice = CheckNamespace(temp, namespaces)
+	result, nsSlice := CheckNamespace(strings.ToLower(namespace), namespaces)
+	if result {
+		return strings.ToLower(namespace)
+	}
+	if !opts.interactive || len(nsSlice) == 0 {
+		fmt.Printf("Given namespace %s is not found. Matching namespaces: %s\n", namespace, nsSlice)
+		logger.Fatalf("Given namespace %s is not found", namespace)
+	}
+	return promptNamespaceChoice(nsSlice)
+}
 
-	count := 4
-	if !result && len(nsSlice) == 0 {
-		for count > 0 {
-			fmt.Println("Given namespace is not found. Please enter valid namespace:")
-			_, err := fmt.Scanln(&namespace)
-			if err != nil {
-				logger.Fatalf("Entering valid namespace failed with error: %s", err.Error())
-			}
-			temp = strings.ToLower(namespace)
-			result, nsSlice = CheckNamespace(temp, namespaces)
-			if result || len(nsSlice) > 0 {
-				break
-			}
-			count--
-		}
+// getOptionalFlag decides if the optional logs are to be collected
+func getOptionalFlag(opts *collectOptions) {
+	if opts.optionalSet {
+		return
+	}
+	// the days filter is meaningful only for optional logs
+	if opts.noOfDaysSet {
+		opts.optional = true
+		return
 	}
+	if opts.interactive {
+		opts.optional = promptOptionalFlag()
+	}
+}
 
-	CheckCount(count)
+// getNoOfDays validates the number of days given through the flag or the prompt
+func getNoOfDays(opts *collectOptions) {
+	var err error
+	if !opts.optional {
+		opts.noOfDays = -1
+		return
+	}
+	if !opts.noOfDaysSet && opts.interactive {
+		opts.noOfDays = promptNoOfDays()
+	}
+	opts.noOfDays, err = CheckNoOfDays(opts.noOfDays)
+	if err != nil {
+		fmt.Println("Invalid number of days, please enter between 1 to 180.")
+		logger.Fatalf("Invalid number of days, please enter between 1 to 180.")
+	}
+	fmt.Printf("Logs will be collected for past %d days from today\n", opts.noOfDays)
+}
 
-	if !result {
-		count = 4
-		for count > 0 {
-			index := 0
-			fmt.Println("Please select the correct namespace from the below choices:")
-			for i, x := range nsSlice {
-				fmt.Printf("%d. %s\n", i+1, x)
-			}
-			fmt.Println("Enter the choice:")
-			_, err := fmt.Scanln(&index)
-			if err != nil {
-				logger.Fatalf("Entering namespace failed with error: %s", err.Error())
-			}
-			if index < 1 || index > len(nsSlice) {
-				fmt.Println("Please select valid namespace")
-				count--
-			} else {
-				temp = nsSlice[index-1]
-				break
-			}
+// ParseDriverChoice returns the registered CSI driver name for the given menu number, driver name or alias
+func ParseDriverChoice(driverOption string) (string, error) {
+	driverOption = strings.ToLower(strings.TrimSpace(driverOption))
+	drivers := csm.GetDrivers()
+	if driverChoice, err := strconv.Atoi(driverOption); err == nil {
+		if driverChoice < 1 || driverChoice > len(drivers) {
+			return "", fmt.Errorf("invalid CSI driver choice: %d", driverChoice)
 		}
+		return drivers[driverChoice-1].Name, nil
 	}
+	driver, ok := csm.LookupDriver(driverOption)
+	if !ok {
+		return "", fmt.Errorf("unsupported CSI driver: %s", driverOption)
+	}
+	return driver.Name, nil
+}
 
-	CheckCount(count)
-
-	count = 4
-
-	for count > 0 {
-		fmt.Println("\nOptional log will be collected only when True/true is entered. Supported values are True/true/False/false.")
-		ipCount, err = fmt.Scanln(&optionalFlag)
-		if err != nil || ipCount <= 0 {
-			fmt.Printf("Invalid input or failed to get user input. Please retry !!")
-			if count <= 0 {
-				logger.Fatalf("Getting Optiona log user input failed with error: %s", err.Error())
+// PairDriverNamespaces pairs the repeated --driver and --namespace flags in their order, a driver:namespace
+// value of --driver gives both at once. A single driver or namespace may be given alone, the other one is prompted.
+func PairDriverNamespaces(drivers []string, namespaces []string) ([]driverNamespace, error) {
+	var pairs []driverNamespace
+	var unpaired []string
+	for _, driver := range drivers {
+		if i := strings.Index(driver, ":"); i >= 0 {
+			pairs = append(pairs, driverNamespace{driver: driver[:i], namespace: driver[i+1:]})
+			if pairs[len(pairs)-1].driver == "" || pairs[len(pairs)-1].namespace == "" {
+				return nil, fmt.Errorf("invalid driver:namespace value: %s", driver)
 			}
+			continue
 		}
-		if optionalFlag == "True" || optionalFlag == "true" || optionalFlag == "False" || optionalFlag == "false" {
-			break
+		unpaired = append(unpaired, driver)
+	}
+	switch {
+	case len(unpaired) == len(namespaces):
+		for i := range unpaired {
+			pairs = append(pairs, driverNamespace{driver: unpaired[i], namespace: namespaces[i]})
+		}
+	case len(pairs) == 0 && len(unpaired) <= 1 && len(namespaces) <= 1:
+		pair := driverNamespace{}
+		if len(unpaired) == 1 {
+			pair.driver = unpaired[0]
+		}
+		if len(namespaces) == 1 {
+			pair.namespace = namespaces[0]
 		}
-		count--
+		pairs = append(pairs, pair)
+	default:
+		return nil, fmt.Errorf("%d --driver given for %d --namespace", len(unpaired), len(namespaces))
 	}
+	return pairs, nil
+}
 
-	CheckCount(count)
-
-	daysUserInput := ""
-	noOfDays := -1
-	var intErr error
-
-	if optionalFlag == "True" || optionalFlag == "true" {
-		fmt.Println("Enter the number of days the logs need to be collected from today (to skip this filter enter 0) :")
-		ipCount, inputErr := fmt.Scanln(&daysUserInput)
-		noOfDays, intErr = strconv.Atoi(daysUserInput)
-		if inputErr != nil || intErr != nil || noOfDays < 0 || noOfDays > 180 || ipCount <= 0 {
-			fmt.Println("Invalid number of days, please enter between 1 to 180.")
-			logger.Fatalf("Invalid number of days, please enter between 1 to 180.")
+// ParseInstallChoice returns the indexes of the discovered CSI drivers selected by their comma separated numbers,
+// 'all' selects all of them and an empty choice none of them
+func ParseInstallChoice(choice string, count int) ([]int, error) {
+	choice = strings.ToLower(strings.TrimSpace(choice))
+	var indexes []int
+	switch choice {
+	case "":
+		return nil, nil
+	case "all", "y":
+		for i := 0; i < count; i++ {
+			indexes = append(indexes, i)
 		}
-
-		if noOfDays == 0 {
-			noOfDays = 180
+		return indexes, nil
+	}
+	selected := make(map[int]bool)
+	for _, number := range strings.Split(choice, ",") {
+		index, err := strconv.Atoi(strings.TrimSpace(number))
+		if err != nil || index < 1 || index > count {
+			return nil, fmt.Errorf("invalid CSI driver choice: %s", number)
+		}
+		if !selected[index] {
+			selected[index] = true
+			indexes = append(indexes, index-1)
 		}
-		fmt.Printf("Logs will be collected for past %d days from today\n", noOfDays)
 	}
+	return indexes, nil
+}
 
-	switch {
-	case driveChoice == 1:
-		p = csm.PowerScaleStruct{}
-	case driveChoice == 2:
-		p = csm.UnityStruct{}
-	case driveChoice == 3:
-		p = csm.PowerStoreStruct{}
-	case driveChoice == 4:
-		p = csm.PowerMaxStruct{}
-	case driveChoice == 5:
-		p = csm.PowerFlexStruct{}
-	default:
-		{
-			fmt.Println("Invalid choice, please enter valid choice")
-			logger.Fatalf("Invalid choice, exiting Application")
+// ParseModules returns the CSM module names for the comma separated list, nil selects all the modules
+func ParseModules(modules string) ([]string, error) {
+	modules = strings.ToLower(strings.TrimSpace(modules))
+	switch modules {
+	case "all":
+		return nil, nil
+	case "none", "":
+		return []string{}, nil
+	}
+	var names []string
+	for _, name := range strings.Split(modules, ",") {
+		module, ok := csm.LookupModule(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported CSM module: %s", name)
 		}
+		names = append(names, module.Name)
+	}
+	return names, nil
+}
+
+// CheckNoOfDays verifies the number of days is in the supported range, 0 skips the date filter
+func CheckNoOfDays(noOfDays int) (int, error) {
+	if noOfDays < 0 || noOfDays > maxNoOfDays {
+		return 0, fmt.Errorf("invalid number of days: %d", noOfDays)
+	}
+	if noOfDays == 0 {
+		noOfDays = maxNoOfDays
+	}
+	return noOfDays, nil
+}
+
+// CheckParallelism verifies the number of concurrent workers
+func CheckParallelism(parallelism int) error {
+	if parallelism < 1 {
+		return fmt.Errorf("invalid parallelism: %d", parallelism)
 	}
+	return nil
+}
 
-	p.GetLogs(temp, optionalFlag, noOfDays, driveChoice)
+// CheckOptionalFlag verifies the optional flag input
+func CheckOptionalFlag(optionalFlag string) (bool, error) {
+	if optionalFlag == "True" || optionalFlag == "true" || optionalFlag == "False" || optionalFlag == "false" {
+		return strconv.ParseBool(optionalFlag)
+	}
+	return false, fmt.Errorf("invalid optional flag: %s", optionalFlag)
 }
 
 // CheckNamespace verifies if given namespace exists
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDriverChoice(t *testing.T) {
+	type tests = []struct {
+		description    string
+		driver         string
+		expectedDriver string
+		expectError    bool
+	}
+
+	var driverChoiceTests = tests{
+		{"driver choice by menu number", "1", "powerscale", false},
+		{"driver choice by name", "powermax", "powermax", false},
+		{"driver choice by alias", "VxFlexOS", "powerflex", false},
+		{"invalid driver choice", "6", "", true},
+		{"invalid driver name", "csi-unknown", "", true},
+	}
+
+	for _, test := range driverChoiceTests {
+		t.Run(test.description, func(t *testing.T) {
+			actual, err := ParseDriverChoice(test.driver)
+			if (err != nil) != test.expectError {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if diff := cmp.Diff(actual, test.expectedDriver); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedDriver, diff)
+				return
+			}
+		})
+	}
+}
+
+func TestCheckNoOfDays(t *testing.T) {
+	type tests = []struct {
+		description  string
+		noOfDays     int
+		expectedDays int
+		expectError  bool
+	}
+
+	var noOfDaysTests = tests{
+		{"days filter skipped", 0, 180, false},
+		{"days in range", 7, 7, false},
+		{"days above range", 181, 0, true},
+		{"negative days", -1, 0, true},
+	}
+
+	for _, test := range noOfDaysTests {
+		t.Run(test.description, func(t *testing.T) {
+			actual, err := CheckNoOfDays(test.noOfDays)
+			if (err != nil) != test.expectError {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if diff := cmp.Diff(actual, test.expectedDays); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedDays, diff)
+				return
+			}
+		})
+	}
+}
+
+func TestCheckNamespace(t *testing.T) {
+	namespaces := []string{"csi-powermax", "csi-powerstore", "default"}
+	type tests = []struct {
+		description     string
+		namespace       string
+		expectedResult  bool
+		expectedMatches []string
+	}
+
+	var namespaceTests = tests{
+		{"namespace found", "default", true, nil},
+		{"partial namespace match", "csi-power", false, []string{"csi-powermax", "csi-powerstore"}},
+		{"namespace not found", "unity", false, nil},
+	}
+
+	for _, test := range namespaceTests {
+		t.Run(test.description, func(t *testing.T) {
+			result, nsSlice := CheckNamespace(test.namespace, namespaces)
+			if diff := cmp.Diff(result, test.expectedResult); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedResult, diff)
+				return
+			}
+			if diff := cmp.Diff(nsSlice, test.expectedMatches); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedMatches, diff)
+				return
+			}
+		})
+	}
+}
+
+func TestParseModules(t *testing.T) {
+	type tests = []struct {
+		description     string
+		modules         string
+		expectedModules []string
+		expectError     bool
+	}
+
+	var parseModulesTests = tests{
+		{"all modules", "all", nil, false},
+		{"no module", "none", []string{}, false},
+		{"module name", "Authorization", []string{"authorization"}, false},
+		{"unsupported module", "authorization,unknown", nil, true},
+	}
+
+	for _, test := range parseModulesTests {
+		t.Run(test.description, func(t *testing.T) {
+			actual, err := ParseModules(test.modules)
+			if (err != nil) != test.expectError {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if diff := cmp.Diff(actual, test.expectedModules); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedModules, diff)
+				return
+			}
+		})
+	}
+}
+
+func TestPairDriverNamespaces(t *testing.T) {
+	type tests = []struct {
+		description   string
+		drivers       []string
+		namespaces    []string
+		expectedPairs []driverNamespace
+		expectError   bool
+	}
+
+	var pairTests = tests{
+		{"no driver and namespace", nil, nil, nil, false},
+		{"single driver prompting the namespace", []string{"powerstore"}, nil, []driverNamespace{{driver: "powerstore"}}, false},
+		{"repeated drivers and namespaces", []string{"powerstore", "powerscale"}, []string{"csi-powerstore", "isilon"},
+			[]driverNamespace{{"powerstore", "csi-powerstore"}, {"powerscale", "isilon"}}, false},
+		{"driver:namespace values", []string{"powerstore:csi-powerstore", "unity:unity"}, nil,
+			[]driverNamespace{{"powerstore", "csi-powerstore"}, {"unity", "unity"}}, false},
+		{"unpaired drivers", []string{"powerstore", "powerscale"}, []string{"csi-powerstore"}, nil, true},
+		{"invalid driver:namespace value", []string{"powerstore:"}, nil, nil, true},
+	}
+
+	for _, test := range pairTests {
+		t.Run(test.description, func(t *testing.T) {
+			actual, err := PairDriverNamespaces(test.drivers, test.namespaces)
+			if (err != nil) != test.expectError {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if diff := cmp.Diff(actual, test.expectedPairs, cmp.AllowUnexported(driverNamespace{})); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedPairs, diff)
+				return
+			}
+		})
+	}
+}
+
+func TestParseInstallChoice(t *testing.T) {
+	type tests = []struct {
+		description     string
+		choice          string
+		expectedIndexes []int
+		expectError     bool
+	}
+
+	var installChoiceTests = tests{
+		{"no driver selected", "\n", nil, false},
+		{"all drivers selected", "all\n", []int{0, 1, 2}, false},
+		{"subset of drivers selected", "3, 1,3\n", []int{2, 0}, false},
+		{"invalid driver number", "1,4", nil, true},
+	}
+
+	for _, test := range installChoiceTests {
+		t.Run(test.description, func(t *testing.T) {
+			actual, err := ParseInstallChoice(test.choice, 3)
+			if (err != nil) != test.expectError {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if diff := cmp.Diff(actual, test.expectedIndexes); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedIndexes, diff)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+/*
+ Copyright © 2022 Dell Inc. or its subsidiaries. All Rights Reserved.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"csm-logcollector/csm"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isInteractive verifies if a terminal is attached to the standard input
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func promptConsent() bool {
+	var consent string
+	fmt.Println(consentMsg)
+	ipCount, err := fmt.Scanln(&consent)
+	return err == nil && ipCount > 0 && (consent == "Y" || consent == "y")
+}
+
+// promptDriverInstalls returns the indexes of the discovered CSI drivers selected by the user,
+// none of them when the user prefers to enter the driver and namespace
+func promptDriverInstalls(installCount int) []int {
+	count := 4
+	for count > 0 {
+		fmt.Println("\nPlease enter the numbers of the discovered CSI drivers to collect separated by commas (e.g. 1,3), 'all' for all of them,")
+		fmt.Println("or press Enter to enter the CSI driver and namespace:")
+		choice, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		indexes, err := ParseInstallChoice(choice, installCount)
+		if err == nil {
+			return indexes
+		}
+		fmt.Printf("Invalid choice: %s\n", err.Error())
+		count--
+	}
+
+	CheckCount(count)
+	return nil
+}
+
+func promptAnotherDriver() bool {
+	var answer string
+	fmt.Println("\nDo you want to collect the logs of another CSI driver in the same bundle? (Y/y)")
+	ipCount, err := fmt.Scanln(&answer)
+	return err == nil && ipCount > 0 && (answer == "Y" || answer == "y")
+}
+
+func promptDriver() string {
+	driveOption := ""
+	fmt.Println("Please select the respective storage array for which CSI Driver logs need to be collected:")
+	drivers := csm.GetDrivers()
+	for i, driver := range drivers {
+		fmt.Printf("%d: %s\n", i+1, driver.DisplayName)
+	}
+	fmt.Printf("\nPlease enter your choice (e.g. enter '1' for %s) :\n", drivers[0].DisplayName)
+	ipCount, err := fmt.Scanln(&driveOption)
+	if err != nil || ipCount <= 0 {
+		fmt.Println("Invalid choice, please enter correct choice")
+		logger.Fatalf("Entering CSI Driver choice failed")
+	}
+	return driveOption
+}
+
+func promptNamespace(namespaces []string) string {
+	var namespace string
+	fmt.Println("\nEnter the namespace: ")
+	_, errns := fmt.Scanln(&namespace)
+	if errns != nil {
+		fmt.Printf("\nEntering namespace failed with error %s \n", errns.Error())
+		logger.Fatalf("Entering namespace failed with error: %s", errns.Error())
+	}
+	temp := strings.ToLower(namespace)
+	result, nsSlice := CheckNamespace(temp, namespaces)
+
+	count := 4
+	if !result && len(nsSlice) == 0 {
+		for count > 0 {
+			fmt.Println("Given namespace is not found. Please enter valid namespace:")
+			_, err := fmt.Scanln(&namespace)
+			if err != nil {
+				logger.Fatalf("Entering valid namespace failed with error: %s", err.Error())
+			}
+			temp = strings.ToLower(namespace)
+			result, nsSlice = CheckNamespace(temp, namespaces)
+			if result || len(nsSlice) > 0 {
+				break
+			}
+			count--
+		}
+	}
+
+	CheckCount(count)
+
+	if !result {
+		temp = promptNamespaceChoice(nsSlice)
+	}
+	return temp
+}
+
+func promptNamespaceChoice(nsSlice []string) string {
+	count := 4
+	namespace := ""
+	for count > 0 {
+		index := 0
+		fmt.Println("Please select the correct namespace from the below choices:")
+		for i, x := range nsSlice {
+			fmt.Printf("%d. %s\n", i+1, x)
+		}
+		fmt.Println("Enter the choice:")
+		_, err := fmt.Scanln(&index)
+		if err != nil {
+			logger.Fatalf("Entering namespace failed with error: %s", err.Error())
+		}
+		if index < 1 || index > len(nsSlice) {
+			fmt.Println("Please select valid namespace")
+			count--
+		} else {
+			namespace = nsSlice[index-1]
+			break
+		}
+	}
+
+	CheckCount(count)
+	return namespace
+}
+
+func promptOptionalFlag() bool {
+	var optionalFlag string
+	count := 4
+	for count > 0 {
+		fmt.Println("\nOptional log will be collected only when True/true is entered. Supported values are True/true/False/false.")
+		ipCount, err := fmt.Scanln(&optionalFlag)
+		if err != nil || ipCount <= 0 {
+			fmt.Printf("Invalid input or failed to get user input. Please retry !!")
+		}
+		if optional, err := CheckOptionalFlag(optionalFlag); err == nil {
+			return optional
+		}
+		count--
+	}
+
+	CheckCount(count)
+	return false
+}
+
+func promptNoOfDays() int {
+	daysUserInput := ""
+	fmt.Println("Enter the number of days the logs need to be collected from today (to skip this filter enter 0) :")
+	ipCount, inputErr := fmt.Scanln(&daysUserInput)
+	noOfDays, intErr := strconv.Atoi(daysUserInput)
+	if inputErr != nil || intErr != nil || ipCount <= 0 {
+		fmt.Println("Invalid number of days, please enter between 1 to 180.")
+		logger.Fatalf("Invalid number of days, please enter between 1 to 180.")
+	}
+	return noOfDays
+}
+
+// waitForReproduction returns once the window is over or Enter is pressed, a zero window waits for Enter only
+func waitForReproduction(window time.Duration) {
+	entered := make(chan struct{})
+	if isInteractive() {
+		go func() {
+			var input string
+			_, _ = fmt.Scanln(&input)
+			close(entered)
+		}()
+	}
+	if window == 0 {
+		fmt.Println("\nPlease reproduce the issue now, press Enter once it is reproduced.")
+		<-entered
+		return
+	}
+	fmt.Printf("\nPlease reproduce the issue now, the logs will be collected in %s or once Enter is pressed.\n", window)
+	select {
+	case <-time.After(window):
+	case <-entered:
+	}
+}
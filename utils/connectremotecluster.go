@@ -16,11 +16,13 @@ package utils
 import (
 	"encoding/base64"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +35,14 @@ import (
 // Logging object
 var remoteClusterLog, _ = GetLogger()
 
+// remoteCluster enables the copies of the kubeconfig and of the secret files from the cluster over SSH
+var remoteCluster bool
+
+// SetRemoteCluster enables the copies of the files from the cluster over SSH
+func SetRemoteCluster(remote bool) {
+	remoteCluster = remote
+}
+
 // GetLocalIP get the IP address of the current system
 func GetLocalIP() (string, error) {
 	ifaces, err := net.Interfaces()
@@ -71,30 +81,22 @@ func GetLocalIP() (string, error) {
 	return "", err
 }
 
+// sshTimeout is the time given to establish the SSH connections
+const sshTimeout = 30 * time.Second
+
 // Connect method creates a connection with the remote cluster
-func Connect(user, password, host string, port int) (*sftp.Client, error) {
+func Connect(sshConfig SSHConfig, host string) (*sftp.Client, error) {
 	var (
-		addr         string
-		clientConfig *ssh.ClientConfig
-		sshClient    *ssh.Client
-		sftpClient   *sftp.Client
-		err          error
+		sshClient  *ssh.Client
+		sftpClient *sftp.Client
+		err        error
 	)
 
-	// Define the Client Config
-	clientConfig = &ssh.ClientConfig{
-		User:            user,
-		Auth:            []ssh.AuthMethod{ssh.Password(password)},
-		Timeout:         30 * time.Second,
-		HostKeyCallback: trustedHostKeyCallback(""),
-	}
-
 	// connect to ssh
-	addr = fmt.Sprintf("%s:%d", host, port)
-	sshClient, err = ssh.Dial("tcp", addr, clientConfig)
+	sshClient, err = DialSSH(sshConfig, host)
 	if err != nil {
 		fmt.Println("Failed to connect with remote cluster, please verify remote cluster details and credentials")
-		remoteClusterLog.Fatalf("Failed to connect with remote cluster with error %s" + err.Error())
+		remoteClusterLog.Fatalf("Failed to connect with remote cluster with error %s", err.Error())
 	}
 	remoteClusterLog.Info("Successfully connected to ssh server.")
 
@@ -106,6 +108,81 @@ func Connect(user, password, host string, port int) (*sftp.Client, error) {
 	return sftpClient, nil
 }
 
+// DialSSH creates an SSH connection with the host, tunnelled through the jump hosts of the configuration,
+// the host keys are verified unless the verification is disabled
+func DialSSH(sshConfig SSHConfig, host string) (*ssh.Client, error) {
+	hops := make([]JumpHost, 0, len(sshConfig.JumpHosts)+1)
+	hops = append(hops, sshConfig.JumpHosts...)
+	hops = append(hops, JumpHost{Address: host, SSHConfig: sshConfig})
+
+	var jumpClients []*ssh.Client
+	closeJumpClients := func() {
+		for i := len(jumpClients) - 1; i >= 0; i-- {
+			_ = jumpClients[i].Close()
+		}
+	}
+	var client *ssh.Client
+	for i, hop := range hops {
+		next, err := dialHop(client, hop)
+		if err != nil {
+			closeJumpClients()
+			if i < len(hops)-1 {
+				return nil, fmt.Errorf("connecting to jump host %s failed with error: %s", hop.Address, err.Error())
+			}
+			return nil, err
+		}
+		client = next
+		// the jump clients are tracked as soon as connected, to be closed when a later hop fails
+		if i < len(hops)-1 {
+			jumpClients = append(jumpClients, client)
+		}
+	}
+	if len(jumpClients) > 0 {
+		// the tunnels are closed along with the connection with the host
+		go func() {
+			_ = client.Wait()
+			closeJumpClients()
+		}()
+	}
+	return client, nil
+}
+
+// dialHop connects to the hop directly or through the tunnel of the previous hop
+func dialHop(via *ssh.Client, hop JumpHost) (*ssh.Client, error) {
+	clientConfig, release, err := hop.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	address := net.JoinHostPort(hop.Address, strconv.Itoa(hop.port()))
+	if via == nil {
+		return ssh.Dial("tcp", address, clientConfig)
+	}
+	conn, err := via.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, address, clientConfig)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(clientConn, channels, requests), nil
+}
+
+// RunCommand runs the command on the host over the SSH connection, its standard output and error are
+// streamed to the output, which must be safe for concurrent writes
+func RunCommand(sshClient *ssh.Client, command string, output io.Writer) error {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdout = output
+	session.Stderr = output
+	return session.Run(command)
+}
+
 // create human-readable SSH-key strings
 func keyString(k ssh.PublicKey) string {
 	return k.Type() + " " + base64.StdEncoding.EncodeToString(k.Marshal()) // e.g. "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTY...."
@@ -131,7 +208,7 @@ func trustedHostKeyCallback(trustedKey string) ssh.HostKeyCallback {
 }
 
 // ScpConfigFile performs the operation to download the config file from remote cluster to container node
-func ScpConfigFile(kubeconfigPath string, clusterIPAddress string, clusterUsername string, clusterPassword string, destinationPath string) string {
+func ScpConfigFile(kubeconfigPath string, clusterIPAddress string, sshConfig SSHConfig, destinationPath string) string {
 	var dstinationFileName = ""
 	isCopied := false
 	var (
@@ -140,7 +217,7 @@ func ScpConfigFile(kubeconfigPath string, clusterIPAddress string, clusterUserna
 	)
 
 	// change to the actual SSH connection user name, password, host name or IP, SSH port
-	sftpClient, err = Connect(clusterUsername, clusterPassword, clusterIPAddress, 22)
+	sftpClient, err = Connect(sshConfig, clusterIPAddress)
 	if err != nil {
 		remoteClusterLog.Fatalf("Error: %s", err)
 	}
@@ -210,25 +287,9 @@ func createDirectory(name string) (dirName string) {
 // UpdateFileName method suffixes the driver name along with secret/config file of driver
 func UpdateFileName(filePath string) string {
 	var secretFilePath string
-	if strings.Contains(filePath, "unity") {
+	if layout, ok := getSecretLayout(filePath); ok {
 		str := strings.SplitN(filePath, ".", 2)
-		secretFilePath = str[0] + "-unity." + str[1]
-	}
-	if strings.Contains(filePath, "powerscale") {
-		str := strings.SplitN(filePath, ".", 2)
-		secretFilePath = str[0] + "-powerscale." + str[1]
-	}
-	if strings.Contains(filePath, "powerstore") {
-		str := strings.SplitN(filePath, ".", 2)
-		secretFilePath = str[0] + "-powerstore." + str[1]
-	}
-	if strings.Contains(filePath, "powermax") {
-		str := strings.SplitN(filePath, ".", 2)
-		secretFilePath = str[0] + "-powermax." + str[1]
-	}
-	if strings.Contains(filePath, "powerflex") {
-		str := strings.SplitN(filePath, ".", 2)
-		secretFilePath = str[0] + "-powerflex." + str[1]
+		secretFilePath = str[0] + "-" + layout.FileTag + "." + str[1]
 	}
 	return secretFilePath
 }
@@ -260,24 +321,19 @@ func GetRemoteClusterDetails() (string, string, string) {
 				}
 
 				for key, value := range kubeconfigDetails {
-					// type assertion from interface{} type to string type
-					key, ok1 := key.(string)
-					value, ok2 := value.(string)
-					if !ok1 || !ok2 {
-						remoteClusterLog.Fatalf("key/value is not string!")
-					}
-					if len(strings.TrimSpace(value)) != 0 {
-						if key == "ip_address" {
-							ipAddrr = value
-						}
-						if key == "username" {
-							userName = value
-						}
-						if key == "password" {
-							password = value
+					// only ip_address is required, the SSH credentials are validated by the SSH authentication
+					// of GetRemoteSSHConfig, which may use a private key or the ssh-agent instead of the password
+					switch key {
+					case "ip_address":
+						value, ok := value.(string)
+						if !ok || len(strings.TrimSpace(value)) == 0 {
+							remoteClusterLog.Fatalf("No value found for kubeconfig_details sub-key: %s", key)
 						}
-					} else {
-						remoteClusterLog.Fatalf("No value found for kubeconfig_details sub-key: %s", key)
+						ipAddrr = value
+					case "username":
+						userName, _ = value.(string)
+					case "password":
+						password, _ = value.(string)
 					}
 				}
 			}
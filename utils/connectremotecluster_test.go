@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"testing"
@@ -84,6 +85,7 @@ func TestCreateDirectory(t *testing.T) {
 func TestGetRemoteClusterDetails(t *testing.T) {
 	type tests = []struct {
 		description      string
+		config           string
 		expectedUserName string
 		expectedPassword string
 		expectedIPAddrr  string
@@ -91,24 +93,56 @@ func TestGetRemoteClusterDetails(t *testing.T) {
 	var remoteClusterTests = tests{
 		{
 			"Test for remote cluster details - postitve",
+			"",
 			"sample_user",
 			"sample_password",
 			"xxx.xxx.xxx.xxx",
 		},
+		{
+			"Test for remote cluster details with private key and empty password",
+			"kubeconfig_details:\n  path: /root/.kube/config\n  ip_address: 10.0.0.1\n  username: root\n  password: \"\"\n  private_key: /root/.ssh/id_ed25519\n",
+			"root",
+			"",
+			"10.0.0.1",
+		},
 	}
 	for _, test := range remoteClusterTests {
-		actualIPAddr, actualUsername, actualPassword := GetRemoteClusterDetails()
-		if diff := cmp.Diff(actualIPAddr, test.expectedIPAddrr); diff != "" {
-			t.Errorf("%T differ (-got, +want): %s", test.expectedIPAddrr, diff)
-			return
-		}
-		if diff := cmp.Diff(actualUsername, test.expectedUserName); diff != "" {
-			t.Errorf("%T differ (-got, +want): %s", test.expectedUserName, diff)
-			return
-		}
-		if diff := cmp.Diff(actualPassword, test.expectedPassword); diff != "" {
-			t.Errorf("%T differ (-got, +want): %s", test.expectedPassword, diff)
-			return
-		}
+		t.Run(test.description, func(t *testing.T) {
+			if test.config != "" {
+				directoryName, _ := ioutil.TempDir("", "remote-cluster")
+				defer os.RemoveAll(directoryName)
+				if err := ioutil.WriteFile(directoryName+"/config.yml", []byte(test.config), 0600); err != nil {
+					t.Fatalf("config file not written: %s", err)
+				}
+				workingDirectory, _ := os.Getwd()
+				_ = os.Chdir(directoryName)
+				defer func() { _ = os.Chdir(workingDirectory) }()
+			}
+			actualIPAddr, actualUsername, actualPassword := GetRemoteClusterDetails()
+			if diff := cmp.Diff(actualIPAddr, test.expectedIPAddrr); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedIPAddrr, diff)
+				return
+			}
+			if diff := cmp.Diff(actualUsername, test.expectedUserName); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedUserName, diff)
+				return
+			}
+			if diff := cmp.Diff(actualPassword, test.expectedPassword); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedPassword, diff)
+				return
+			}
+		})
 	}
 }
+
+func TestUpdateFileNameRegisteredLayout(t *testing.T) {
+	t.Run("Update registered driver secret file's name", func(t *testing.T) {
+		RegisterSecretLayout(SecretLayout{DriverPathKey: "csi-powerstore-nfs", SecretFile: "samples/secret/secret.yaml", FileTag: "powerstore-nfs"})
+		defer delete(secretLayouts, "csi-powerstore-nfs")
+		expectedFilePath := "/root/csi-powerstore-nfs/samples/secret/secret-powerstore-nfs.yaml"
+		actualFilePath := UpdateFileName("/root/csi-powerstore-nfs/samples/secret/secret.yaml")
+		if diff := cmp.Diff(actualFilePath, expectedFilePath); diff != "" {
+			t.Errorf("%T differ (-got, +want): %s", expectedFilePath, diff)
+		}
+	})
+}
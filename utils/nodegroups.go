@@ -0,0 +1,74 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NodeGroup holds the SSH details of a group of cluster nodes used for the node diagnostics
+type NodeGroup struct {
+	// Name identifies the node group in the messages
+	Name string `yaml:"name"`
+	// Nodes are the node name patterns of the group, e.g. worker-*, a group without patterns matches every node
+	Nodes     []string `yaml:"nodes"`
+	SSHConfig `yaml:",inline"`
+}
+
+// GetNodeGroups reads the node groups of the node_diagnostics section of the application configuration file
+func GetNodeGroups() []NodeGroup {
+	var config struct {
+		NodeDiagnostics struct {
+			NodeGroups []NodeGroup `yaml:"node_groups"`
+		} `yaml:"node_diagnostics"`
+	}
+	_, err := os.Stat("config.yml")
+	if err != nil {
+		return nil
+	}
+	yamlFile, err := ioutil.ReadFile("config.yml")
+	if err != nil {
+		remoteClusterLog.Fatalf("Reading configuration file failed with error %v ", err)
+	}
+	err = yaml.Unmarshal(yamlFile, &config)
+	if err != nil {
+		remoteClusterLog.Fatalf("Unmarshalling configuration file failed with error %v", err)
+	}
+	for i, group := range config.NodeDiagnostics.NodeGroups {
+		if len(strings.TrimSpace(group.Username)) == 0 {
+			remoteClusterLog.Fatalf("No username found for node_diagnostics node group %d %s", i+1, group.Name)
+		}
+	}
+	return config.NodeDiagnostics.NodeGroups
+}
+
+// MatchNodeGroup returns the first node group matching the node name
+func MatchNodeGroup(nodeGroups []NodeGroup, nodeName string) (NodeGroup, bool) {
+	for _, group := range nodeGroups {
+		if len(group.Nodes) == 0 {
+			return group, true
+		}
+		for _, pattern := range group.Nodes {
+			if matched, _ := path.Match(pattern, nodeName); matched {
+				return group, true
+			}
+		}
+	}
+	return NodeGroup{}, false
+}
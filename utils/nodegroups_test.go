@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetNodeGroups(t *testing.T) {
+	t.Run("Get node groups from config file", func(t *testing.T) {
+		expected := []string{"workers", "default"}
+		var actual []string
+		for _, group := range GetNodeGroups() {
+			actual = append(actual, group.Name)
+		}
+		if diff := cmp.Diff(actual, expected); diff != "" {
+			t.Errorf("%T differ (-got, +want): %s", expected, diff)
+		}
+	})
+}
+
+func TestMatchNodeGroup(t *testing.T) {
+	type tests = []struct {
+		description string
+		nodeGroups  []NodeGroup
+		nodeName    string
+		expected    string
+		matched     bool
+	}
+	workers := NodeGroup{Name: "workers", Nodes: []string{"worker-*"}}
+	masters := NodeGroup{Name: "masters", Nodes: []string{"master-1", "master-2"}}
+	defaults := NodeGroup{Name: "default"}
+	var matchNodeGroupTests = tests{
+		{"node name matches pattern", []NodeGroup{workers, masters}, "worker-3", "workers", true},
+		{"node name matches name", []NodeGroup{workers, masters}, "master-2", "masters", true},
+		{"group without patterns matches any node", []NodeGroup{workers, defaults}, "infra-1", "default", true},
+		{"no group matches", []NodeGroup{workers, masters}, "infra-1", "", false},
+	}
+	for _, test := range matchNodeGroupTests {
+		t.Run(test.description, func(t *testing.T) {
+			group, matched := MatchNodeGroup(test.nodeGroups, test.nodeName)
+			if diff := cmp.Diff(group.Name, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+			if diff := cmp.Diff(matched, test.matched); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.matched, diff)
+			}
+		})
+	}
+}
@@ -15,9 +15,11 @@ package utils
 
 import (
 	// "csm-logcollector/csm"
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -67,13 +69,12 @@ func GetSecretFilePath() []string {
 				}
 
 				if len(strings.TrimSpace(value)) != 0 {
-					// secret.yml file relative path is same for unity, powerscale, powerstore and powermax drivers
-					if key == "csi-unity" || key == "csi-powerscale" || key == "csi-powerstore" || key == "csi-powermax" {
-						secretFilePath := value + "/samples/secret/secret.yaml"
-						secretFilePaths = append(secretFilePaths, secretFilePath)
-					} else if key == "csi-powerflex" {
-						secretFilePath := value + "/samples/config.yaml"
+					// secret/config file relative path is registered by each driver
+					if layout, ok := secretLayouts[key]; ok {
+						secretFilePath := value + "/" + layout.SecretFile
 						secretFilePaths = append(secretFilePaths, secretFilePath)
+					} else {
+						sanityLog.Warnf("driver_path sub-key %s does not match any supported driver.", key)
 					}
 				} else {
 					sanityLog.Warnf("driver_path sub-key for %s is empty. Hence it's secret file can't be obtained.", key)
@@ -126,6 +127,81 @@ func GetSecretOpted() bool {
 	return useSecrets
 }
 
+// SecretLayout describes the secret/config file of a CSI driver used for the sanitization
+type SecretLayout struct {
+	// DriverPathKey is the driver_path sub-key of config.yml, e.g. csi-powermax
+	DriverPathKey string
+	// SecretFile is the path of the secret/config file relative to the driver path
+	SecretFile string
+	// FileTag identifies the driver in the secret/config file path and is suffixed to the copied file name
+	FileTag string
+	// ReadContent identifies the sensitive content present in the secret/config file
+	ReadContent func(filePath string, fileContent []byte, sensitiveContentList []string) []string
+}
+
+// Secret/config file layouts of the supported drivers
+var (
+	UnitySecretLayout = SecretLayout{DriverPathKey: "csi-unity", SecretFile: "samples/secret/secret.yaml", FileTag: "unity",
+		ReadContent: func(filePath string, fileContent []byte, sensitiveContentList []string) []string {
+			return UnitySecretContent(unmarshalSecretFile(filePath, fileContent), sensitiveContentList)
+		}}
+	PowerScaleSecretLayout = SecretLayout{DriverPathKey: "csi-powerscale", SecretFile: "samples/secret/secret.yaml", FileTag: "powerscale",
+		ReadContent: func(filePath string, fileContent []byte, sensitiveContentList []string) []string {
+			return PowerscaleSecretContent(unmarshalSecretFile(filePath, fileContent), sensitiveContentList)
+		}}
+	PowerStoreSecretLayout = SecretLayout{DriverPathKey: "csi-powerstore", SecretFile: "samples/secret/secret.yaml", FileTag: "powerstore",
+		ReadContent: func(filePath string, fileContent []byte, sensitiveContentList []string) []string {
+			return PowerstoreSecretContent(unmarshalSecretFile(filePath, fileContent), sensitiveContentList)
+		}}
+	PowerMaxSecretLayout = SecretLayout{DriverPathKey: "csi-powermax", SecretFile: "samples/secret/secret.yaml", FileTag: "powermax",
+		ReadContent: func(filePath string, fileContent []byte, sensitiveContentList []string) []string {
+			return PowermaxSecretContent(unmarshalSecretFile(filePath, fileContent), sensitiveContentList)
+		}}
+	// Powerflex driver has config.yaml which has data as list[map].
+	PowerFlexSecretLayout = SecretLayout{DriverPathKey: "csi-powerflex", SecretFile: "samples/config.yaml", FileTag: "powerflex",
+		ReadContent: func(filePath string, fileContent []byte, sensitiveContentList []string) []string {
+			return PowerflexSecretContent(string(fileContent), sensitiveContentList)
+		}}
+)
+
+var secretLayouts = map[string]SecretLayout{
+	UnitySecretLayout.DriverPathKey:      UnitySecretLayout,
+	PowerScaleSecretLayout.DriverPathKey: PowerScaleSecretLayout,
+	PowerStoreSecretLayout.DriverPathKey: PowerStoreSecretLayout,
+	PowerMaxSecretLayout.DriverPathKey:   PowerMaxSecretLayout,
+	PowerFlexSecretLayout.DriverPathKey:  PowerFlexSecretLayout,
+}
+
+// RegisterSecretLayout registers the secret/config file layout of a CSI driver
+func RegisterSecretLayout(layout SecretLayout) {
+	if layout.DriverPathKey == "" {
+		return
+	}
+	secretLayouts[layout.DriverPathKey] = layout
+}
+
+// getSecretLayout returns the layout whose file tag is present in the given path, the longest tag wins
+func getSecretLayout(filePath string) (SecretLayout, bool) {
+	var matched SecretLayout
+	found := false
+	for _, layout := range secretLayouts {
+		if layout.FileTag != "" && strings.Contains(filePath, layout.FileTag) && len(layout.FileTag) > len(matched.FileTag) {
+			matched = layout
+			found = true
+		}
+	}
+	return matched, found
+}
+
+func unmarshalSecretFile(filePath string, fileContent []byte) map[interface{}]interface{} {
+	data := make(map[interface{}]interface{})
+	err := yaml.Unmarshal(fileContent, data)
+	if err != nil {
+		sanityLog.Fatalf("Unmarshalling secret file %s failed with error %v", filePath, err)
+	}
+	return data
+}
+
 // ReadSecretFileContent reads the content of secret.yaml
 func ReadSecretFileContent(secretFilePaths []string) []string {
 	var sensitiveContentList []string
@@ -133,45 +209,18 @@ func ReadSecretFileContent(secretFilePaths []string) []string {
 		filePath := secretFilePaths[item]
 		_, err := os.Stat(filePath)
 		if err == nil {
-			yamlFile, err := ioutil.ReadFile(filepath.Clean(filePath))
+			fileContent, err := ioutil.ReadFile(filepath.Clean(filePath))
 			if err != nil {
 				sanityLog.Fatalf("Reading secret file %s failed with error %v ", filePath, err)
 			}
 
-			// secret/config YAML reading
-			var data map[interface{}]interface{}
-			var fileData string
-			if strings.Contains(filePath, "powerflex") {
-				// Powerflex driver has config.yaml which has data as list[map].
-				fileContent, err := ioutil.ReadFile(filepath.Clean(filePath))
-				fileData = string(fileContent)
-				if err != nil {
-					sanityLog.Fatalf("Reading secret file %s failed with error %v", filePath, err)
-				}
-			} else {
-				data = make(map[interface{}]interface{})
-				err = yaml.Unmarshal(yamlFile, data)
-				if err != nil {
-					sanityLog.Fatalf("Unmarshalling secret file %s failed with error %v", filePath, err)
-				}
-			}
-
 			// secret/config YAML file content reading begins from here based on the respective files of the driver.
-			if strings.Contains(filePath, "unity") {
-				sensitiveContentList = UnitySecretContent(data, sensitiveContentList)
-			}
-			if strings.Contains(filePath, "powerscale") {
-				sensitiveContentList = PowerscaleSecretContent(data, sensitiveContentList)
-			}
-			if strings.Contains(filePath, "powerstore") {
-				sensitiveContentList = PowerstoreSecretContent(data, sensitiveContentList)
-			}
-			if strings.Contains(filePath, "powermax") {
-				sensitiveContentList = PowermaxSecretContent(data, sensitiveContentList)
-			}
-			if strings.Contains(filePath, "powerflex") {
-				sensitiveContentList = PowerflexSecretContent(fileData, sensitiveContentList)
+			layout, ok := getSecretLayout(filePath)
+			if !ok || layout.ReadContent == nil {
+				sanityLog.Infof("Content parsing skipped for the file %s as it does not belong to a supported driver", filePath)
+				continue
 			}
+			sensitiveContentList = layout.ReadContent(filePath, fileContent, sensitiveContentList)
 		} else {
 			sanityLog.Infof("Content parsing skipped for this file, %s", err)
 		}
@@ -234,9 +283,8 @@ func PowermaxSecretContent(data map[interface{}]interface{}, sensitiveContentLis
 // PowerflexSecretContent method reads the secret file content of powerflex driver and identifies the sensitive content
 func PowerflexSecretContent(fileData string, sensitiveContentList []string) []string {
 	fileDataList := strings.Split(fileData, "\n")
-	sensitiveKeyList := []string{"arrayId", "username", "password", "endpoint", "clusterName", "globalID", "systemID", "allSystemNames", "mdm"}
 	for _, str := range fileDataList {
-		if containsKey(str, sensitiveKeyList) {
+		if containsKey(str, SensitiveKeys) {
 			tempValue1 := strings.SplitN(str, "\"", 2)
 			tempValue2 := tempValue1[1]
 			tempValue := strings.SplitN(tempValue2, "\"", 2)
@@ -256,6 +304,37 @@ func containsKey(str string, list []string) bool {
 	return false
 }
 
+// SensitiveKeys are the keys of the drivers' secret/config files whose values are sensitive
+var SensitiveKeys = []string{"arrayId", "username", "password", "endpoint", "clusterName", "globalID", "systemID", "allSystemNames", "mdm"}
+
+// MaskSensitiveValues masks the values of the sensitive keys found at any level of the YAML/JSON content
+func MaskSensitiveValues(content interface{}) interface{} {
+	switch value := content.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			if isSensitiveKey(k) {
+				value[k] = "*********"
+			} else {
+				value[k] = MaskSensitiveValues(v)
+			}
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = MaskSensitiveValues(value[i])
+		}
+	}
+	return content
+}
+
+func isSensitiveKey(key string) bool {
+	for _, sensitiveKey := range SensitiveKeys {
+		if strings.EqualFold(key, sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
+
 // TypeConversion method performs the type assertion from slice to map.
 // This is specifically done for Unity, PowerStore, PowerScale drivers due to slightly differnt content format of their secret.yml file.
 func TypeConversion(arrayDetailsList []interface{}, sensitiveContentList []string) []string {
@@ -271,13 +350,12 @@ func TypeConversion(arrayDetailsList []interface{}, sensitiveContentList []strin
 
 // IdentifySensitiveContent method performs the identification of sensitive content from specific drivers' secret file
 func IdentifySensitiveContent(arrayDetailsMap map[interface{}]interface{}, sensitiveContentList []string) []string {
-	sensitiveKeyList := []string{"arrayId", "username", "password", "endpoint", "clusterName", "globalID", "systemID", "allSystemNames", "mdm"}
 	for key, value := range arrayDetailsMap {
 		k, ok := key.(string)
 		if !ok {
 			sanityLog.Fatalf("key is not string!")
 		}
-		if contains(k, sensitiveKeyList) {
+		if contains(k, SensitiveKeys) {
 			v, ok := value.(string)
 			if !ok {
 				sanityLog.Fatalf("value is not string!")
@@ -355,9 +433,13 @@ func GetSecrets(clientset kubernetes.Interface, namespace string) []string {
 
 // PerformSanitization method performs the sanitization of all logs files against the sensitive strings identified
 func PerformSanitization(clientset kubernetes.Interface, namespace string, namespaceDirectoryName string) bool {
+	return SanitizeDirectory(namespaceDirectoryName, GetSensitiveContent(clientset, namespace))
+}
+
+// GetSensitiveContent identifies the sensitive strings of the driver secrets and secret/config files
+func GetSensitiveContent(clientset kubernetes.Interface, namespace string) []string {
 	var secretFilePaths []string
 	var sensitiveContentList []string
-	var maskingFlag = false
 	if GetSecretOpted() {
 		fmt.Print("\nGet Secrets opted for sanitisation\n")
 		sensitiveContentList = GetSecrets(clientset, namespace)
@@ -366,73 +448,150 @@ func PerformSanitization(clientset kubernetes.Interface, namespace string, names
 	}
 	secretFilePaths = GetSecretFilePath()
 
-	currentIPAddress, err := GetLocalIP()
-	if err != nil {
-		sanityLog.Fatalf("Error: %s", err)
-	}
-	remoteClusterIPAddress, clusterUsername, clusterPassword := GetRemoteClusterDetails()
-	if currentIPAddress != remoteClusterIPAddress {
+	if remoteCluster {
+		remoteClusterIPAddress, _, _ := GetRemoteClusterDetails()
 		if len(secretFilePaths) > 0 {
 			localDirName := createDirectory("RemoteClusterSecretFiles")
 			for item := range secretFilePaths {
-				ScpConfigFile(secretFilePaths[item], remoteClusterIPAddress, clusterUsername, clusterPassword, localDirName)
+				ScpConfigFile(secretFilePaths[item], remoteClusterIPAddress, GetRemoteSSHConfig(), localDirName)
 			}
 			secretFilePaths = GetRemoteSecretFiles()
 		}
 	}
 
 	sanityLog.Infof("secretFilePaths: %s", secretFilePaths)
-	if len(secretFilePaths) > 0 {
-		sensitiveKeyList := []string{"arrayId", "username", "password", "endpoint", "clusterName", "globalID", "systemID", "allSystemNames", "mdm"}
-		sanityLog.Infof("sensitiveKeyList: %s", sensitiveKeyList)
-		sensitiveContentList = append(sensitiveContentList, ReadSecretFileContent(secretFilePaths)...)
-		err := filepath.Walk(namespaceDirectoryName, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				sanityLog.Info(err)
-				return err
-			}
-			if !info.IsDir() {
-				for str := range sensitiveContentList {
-					// read file
-					fileContent, err := ioutil.ReadFile(filepath.Clean(path))
-					if err != nil {
-						sanityLog.Fatalf("File reading failed with error: %s", err)
-					}
+	if len(secretFilePaths) == 0 {
+		return nil
+	}
+	sanityLog.Infof("sensitiveKeyList: %s", SensitiveKeys)
+	return append(sensitiveContentList, ReadSecretFileContent(secretFilePaths)...)
+}
 
-					var fileData = string(fileContent)
-					var isValueExist bool
-
-					// searching sensitive content with case-insensitive matching
-					re := regexp.MustCompile("(?i)" + sensitiveContentList[str])
-					isValueExist = re.Match(fileContent)
-
-					// masking sensitive content with case-insensitive replacement
-					if isValueExist == true {
-						maskingFlag = true
-						sanityLog.Infof("Sanitization initiated for file: %s", info.Name())
-						fileData = re.ReplaceAllString(fileData, "*********")
-
-						// write back to original file
-						err = ioutil.WriteFile(path, []byte(fileData), 0600)
-						if err != nil {
-							sanityLog.Fatalf("File writing failed with error: %s", err)
-							os.Exit(1)
-						} else {
-							sanityLog.Infof("File: %s is sanitized against the sensitive content present in drivers' secret/config YAML files", info.Name())
-						}
-					}
-				}
-			}
+// SanitizeDirectory masks the sensitive strings in all the files of the directory,
+// the files are rewritten line by line so that large logs are not read into memory
+func SanitizeDirectory(namespaceDirectoryName string, sensitiveContentList []string) bool {
+	var maskingFlag = false
+	if len(sensitiveContentList) == 0 {
+		return maskingFlag
+	}
+	err := filepath.Walk(namespaceDirectoryName, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			sanityLog.Info(err)
+			return err
+		}
+		// compressed logs are sanitized while they are streamed to the disk
+		if info.IsDir() || strings.HasSuffix(path, ".gz") {
 			return nil
-		})
+		}
+		masked, err := sanitizeFile(path, sensitiveContentList)
 		if err != nil {
-			sanityLog.Infof("Error: %s", err)
+			sanityLog.Fatalf("Sanitization of file %s failed with error: %s", path, err)
 		}
-		if maskingFlag {
-			fmt.Printf("Masking sensitive content completed.\n")
-		} else {
-			fmt.Printf("Sanitization not performed, either it was not opted or no sensitive content present found.\n")
+		if masked {
+			maskingFlag = true
+			sanityLog.Infof("File: %s is sanitized against the sensitive content present in drivers' secret/config YAML files", info.Name())
 		}
+		return nil
+	})
+	if err != nil {
+		sanityLog.Infof("Error: %s", err)
+	}
+	if maskingFlag {
+		fmt.Printf("Masking sensitive content completed.\n")
+	} else {
+		fmt.Printf("Sanitization not performed, either it was not opted or no sensitive content present found.\n")
 	}
 	return maskingFlag
 }
+
+// sanitizeFile rewrites the file through the sanitizing writer, the file is replaced only when content is masked
+func sanitizeFile(path string, sensitiveContentList []string) (bool, error) {
+	src, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return false, err
+	}
+	defer src.Close()
+	dst, err := ioutil.TempFile(filepath.Dir(path), ".sanitize-")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(dst.Name())
+
+	w := NewSanitizingWriter(dst, sensitiveContentList)
+	_, err = io.Copy(w, src)
+	if err == nil {
+		err = w.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || !w.Masked() {
+		return false, err
+	}
+	if err := os.Chmod(dst.Name(), 0600); err != nil {
+		return false, err
+	}
+	return true, os.Rename(dst.Name(), path)
+}
+
+// maxSanitizedLine is the size after which a line without a newline is sanitized without waiting for its end
+const maxSanitizedLine = 1 << 20
+
+// SanitizingWriter masks the sensitive strings of the content written through it, line by line
+type SanitizingWriter struct {
+	w        io.Writer
+	patterns []*regexp.Regexp
+	pending  []byte
+	masked   bool
+}
+
+// NewSanitizingWriter returns a writer that masks the sensitive strings with case-insensitive matching
+func NewSanitizingWriter(w io.Writer, sensitiveContentList []string) *SanitizingWriter {
+	s := &SanitizingWriter{w: w}
+	for _, content := range sensitiveContentList {
+		if content == "" {
+			continue
+		}
+		s.patterns = append(s.patterns, regexp.MustCompile("(?i)"+regexp.QuoteMeta(content)))
+	}
+	return s
+}
+
+// Write buffers the content until the end of the line and writes the masked lines
+func (s *SanitizingWriter) Write(p []byte) (int, error) {
+	s.pending = append(s.pending, p...)
+	end := bytes.LastIndexByte(s.pending, '\n') + 1
+	if end == 0 && len(s.pending) >= maxSanitizedLine {
+		end = len(s.pending)
+	}
+	if end > 0 {
+		if err := s.writeMasked(s.pending[:end]); err != nil {
+			return 0, err
+		}
+		s.pending = append(s.pending[:0], s.pending[end:]...)
+	}
+	return len(p), nil
+}
+
+// Close writes the remaining content, the underlying writer is not closed
+func (s *SanitizingWriter) Close() error {
+	err := s.writeMasked(s.pending)
+	s.pending = nil
+	return err
+}
+
+// Masked verifies if any sensitive content was masked
+func (s *SanitizingWriter) Masked() bool {
+	return s.masked
+}
+
+func (s *SanitizingWriter) writeMasked(content []byte) error {
+	for _, re := range s.patterns {
+		if re.Match(content) {
+			s.masked = true
+			content = re.ReplaceAll(content, []byte("*********"))
+		}
+	}
+	_, err := s.w.Write(content)
+	return err
+}
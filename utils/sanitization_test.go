@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -397,3 +398,65 @@ func TestIdentifySensitiveContent(t *testing.T) {
 		})
 	}
 }
+
+func TestSanitizingWriter(t *testing.T) {
+	type tests = []struct {
+		description          string
+		writes               []string
+		sensitiveContentList []string
+		expected             string
+		expectedMasked       bool
+	}
+	var sanitizingWriterTests = tests{
+		{"masked across writes", []string{"user=Ad", "min pass=", "secret.1\nnext line"}, []string{"admin", "secret.1"}, "user=********* pass=*********\nnext line", true},
+		{"case-insensitive masking", []string{"ADMIN logged in\n"}, []string{"admin"}, "********* logged in\n", true},
+		{"regex characters are literal", []string{"secretx1\n"}, []string{"secret.1"}, "secretx1\n", false},
+		{"empty content ignored", []string{"no secrets\n"}, []string{""}, "no secrets\n", false},
+	}
+	for _, test := range sanitizingWriterTests {
+		t.Run(test.description, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewSanitizingWriter(&buf, test.sensitiveContentList)
+			for _, content := range test.writes {
+				if _, err := w.Write([]byte(content)); err != nil {
+					t.Errorf("write failed with error: %s", err)
+					return
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Errorf("close failed with error: %s", err)
+				return
+			}
+			if diff := cmp.Diff(buf.String(), test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+			if diff := cmp.Diff(w.Masked(), test.expectedMasked); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedMasked, diff)
+			}
+		})
+	}
+}
+
+func TestMaskSensitiveValues(t *testing.T) {
+	type tests = []struct {
+		description string
+		content     interface{}
+		expected    interface{}
+	}
+	var maskSensitiveValuesTests = tests{
+		{"nested keys masked", map[string]interface{}{"logLevel": "debug", "storageArrays": []interface{}{
+			map[string]interface{}{"endpoint": "https://10.0.0.1", "arrayID": "PS000001"}}},
+			map[string]interface{}{"logLevel": "debug", "storageArrays": []interface{}{
+				map[string]interface{}{"endpoint": "*********", "arrayID": "*********"}}}},
+		{"no sensitive keys", map[string]interface{}{"controller": map[string]interface{}{"replicas": float64(2)}},
+			map[string]interface{}{"controller": map[string]interface{}{"replicas": float64(2)}}},
+	}
+	for _, test := range maskSensitiveValuesTests {
+		t.Run(test.description, func(t *testing.T) {
+			got := MaskSensitiveValues(test.content)
+			if diff := cmp.Diff(got, test.expected); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expected, diff)
+			}
+		})
+	}
+}
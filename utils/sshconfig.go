@@ -0,0 +1,187 @@
+/*
+ Copyright (c) 2022 Dell Inc, or its subsidiaries.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+      http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/util/homedir"
+)
+
+// defaultSSHPort is the port of the SSH connections when none is configured
+const defaultSSHPort = 22
+
+// SSHConfig holds the connection and authentication details of the SSH hosts
+type SSHConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Port is the SSH port of the host, 22 when not set
+	Port int `yaml:"port"`
+	// PrivateKey is the path of the private key file, Passphrase decrypts it when it is encrypted
+	PrivateKey string `yaml:"private_key"`
+	Passphrase string `yaml:"passphrase"`
+	// UseAgent authenticates with the keys of the ssh-agent listening on SSH_AUTH_SOCK
+	UseAgent bool `yaml:"use_agent"`
+	// KnownHosts is the known_hosts file verifying the host key, ~/.ssh/known_hosts when not set
+	KnownHosts string `yaml:"known_hosts"`
+	// HostKeyFingerprint pins the SHA256 fingerprint of the host key instead of the known_hosts file
+	HostKeyFingerprint string `yaml:"host_key_fingerprint"`
+	// JumpHosts are the bastions the connection is tunnelled through, in the order they are reached
+	JumpHosts []JumpHost `yaml:"jump_hosts"`
+}
+
+// JumpHost is a bastion host with its own SSH details, the jump hosts of a jump host are not used
+type JumpHost struct {
+	Address   string `yaml:"address"`
+	SSHConfig `yaml:",inline"`
+}
+
+// insecureIgnoreHostKey disables the verification of the host keys
+var insecureIgnoreHostKey bool
+
+// SetInsecureIgnoreHostKey disables the verification of the host keys of the SSH connections
+func SetInsecureIgnoreHostKey(insecure bool) {
+	insecureIgnoreHostKey = insecure
+}
+
+// GetRemoteSSHConfig reads the SSH details of the remote cluster from kubeconfig_details of the application configuration file
+func GetRemoteSSHConfig() SSHConfig {
+	var config struct {
+		KubeconfigDetails SSHConfig `yaml:"kubeconfig_details"`
+	}
+	_, err := os.Stat("config.yml")
+	if err != nil {
+		return config.KubeconfigDetails
+	}
+	yamlFile, err := ioutil.ReadFile("config.yml")
+	if err != nil {
+		remoteClusterLog.Fatalf("Reading configuration file failed with error %v ", err)
+	}
+	err = yaml.Unmarshal(yamlFile, &config)
+	if err != nil {
+		remoteClusterLog.Fatalf("Unmarshalling configuration file failed with error %v", err)
+	}
+	return config.KubeconfigDetails
+}
+
+// port returns the configured SSH port or the default one
+func (c SSHConfig) port() int {
+	if c.Port == 0 {
+		return defaultSSHPort
+	}
+	return c.Port
+}
+
+// clientConfig returns the SSH client configuration, the returned function releases the ssh-agent connection
+// once the client is connected
+func (c SSHConfig) clientConfig() (*ssh.ClientConfig, func(), error) {
+	release := func() {}
+	var auth []ssh.AuthMethod
+	if c.PrivateKey != "" {
+		signer, err := c.privateKeySigner()
+		if err != nil {
+			return nil, release, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if c.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, release, errors.New("ssh-agent is not available, SSH_AUTH_SOCK is not set")
+		}
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, release, fmt.Errorf("connecting to ssh-agent failed with error: %s", err.Error())
+		}
+		release = func() { _ = conn.Close() }
+		auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+	if c.Password != "" {
+		auth = append(auth, ssh.Password(c.Password))
+	}
+	if len(auth) == 0 {
+		release()
+		return nil, func() {}, errors.New("no SSH authentication configured, please provide a password, a private key or use_agent")
+	}
+
+	hostKeyCallback, err := c.hostKeyCallback()
+	if err != nil {
+		release()
+		return nil, func() {}, err
+	}
+	return &ssh.ClientConfig{
+		User:            c.Username,
+		Auth:            auth,
+		Timeout:         sshTimeout,
+		HostKeyCallback: hostKeyCallback,
+	}, release, nil
+}
+
+// privateKeySigner reads the private key file, decrypting it with the passphrase when given
+func (c SSHConfig) privateKeySigner() (ssh.Signer, error) {
+	key, err := ioutil.ReadFile(filepath.Clean(c.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("reading private key %s failed with error: %s", c.PrivateKey, err.Error())
+	}
+	var signer ssh.Signer
+	if c.Passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(c.Passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key %s failed with error: %s", c.PrivateKey, err.Error())
+	}
+	return signer, nil
+}
+
+// hostKeyCallback verifies the host key against the pinned fingerprint or the known_hosts file,
+// unless the verification is disabled
+func (c SSHConfig) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if insecureIgnoreHostKey {
+		return trustedHostKeyCallback(""), nil
+	}
+	if c.HostKeyFingerprint != "" {
+		return func(host string, _ net.Addr, k ssh.PublicKey) error {
+			if fingerprint := ssh.FingerprintSHA256(k); fingerprint != c.HostKeyFingerprint {
+				return fmt.Errorf("SSH host key verification of %s failed: expected fingerprint %s but got %s", host, c.HostKeyFingerprint, fingerprint)
+			}
+			return nil
+		}, nil
+	}
+	knownHostsFile := c.KnownHosts
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(homedir.HomeDir(), ".ssh", "known_hosts")
+	}
+	callback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("SSH host key verification requires a known_hosts file or host_key_fingerprint: %s", err.Error())
+	}
+	return func(host string, remote net.Addr, k ssh.PublicKey) error {
+		if err := callback(host, remote, k); err != nil {
+			return fmt.Errorf("SSH host key verification of %s against %s failed, host key fingerprint %s: %s",
+				host, knownHostsFile, ssh.FingerprintSHA256(k), err.Error())
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestGetRemoteSSHConfig(t *testing.T) {
+	t.Run("Get SSH details of remote cluster from config file", func(t *testing.T) {
+		expected := SSHConfig{Username: "sample_user", Password: "sample_password", Port: 2222, KnownHosts: "/root/.ssh/known_hosts",
+			JumpHosts: []JumpHost{{Address: "bastion.example.com", SSHConfig: SSHConfig{Username: "jump_user", PrivateKey: "/root/.ssh/id_rsa"}}}}
+		if diff := cmp.Diff(GetRemoteSSHConfig(), expected); diff != "" {
+			t.Errorf("%T differ (-got, +want): %s", expected, diff)
+		}
+	})
+}
+
+func TestHostKeyCallback(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, _ := ssh.NewPublicKey(publicKey)
+	otherPublicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	otherHostKey, _ := ssh.NewPublicKey(otherPublicKey)
+
+	knownHostsFile, _ := ioutil.TempFile("", "known_hosts")
+	defer os.Remove(knownHostsFile.Name())
+	_, _ = knownHostsFile.WriteString(knownhosts.Line([]string{"10.0.0.1"}, hostKey) + "\n")
+	knownHostsFile.Close()
+
+	type tests = []struct {
+		description string
+		config      SSHConfig
+		insecure    bool
+		hostKey     ssh.PublicKey
+		expectedErr bool
+	}
+	var hostKeyCallbackTests = tests{
+		{"pinned fingerprint matches", SSHConfig{HostKeyFingerprint: ssh.FingerprintSHA256(hostKey)}, false, hostKey, false},
+		{"pinned fingerprint differs", SSHConfig{HostKeyFingerprint: ssh.FingerprintSHA256(hostKey)}, false, otherHostKey, true},
+		{"known host key matches", SSHConfig{KnownHosts: knownHostsFile.Name()}, false, hostKey, false},
+		{"known host key differs", SSHConfig{KnownHosts: knownHostsFile.Name()}, false, otherHostKey, true},
+		{"verification disabled", SSHConfig{KnownHosts: knownHostsFile.Name()}, true, otherHostKey, false},
+	}
+	defer SetInsecureIgnoreHostKey(false)
+	for _, test := range hostKeyCallbackTests {
+		t.Run(test.description, func(t *testing.T) {
+			SetInsecureIgnoreHostKey(test.insecure)
+			callback, err := test.config.hostKeyCallback()
+			if err != nil {
+				t.Fatalf("host key callback not created: %s", err)
+			}
+			err = callback("10.0.0.1:22", &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 22}, test.hostKey)
+			if diff := cmp.Diff(err != nil, test.expectedErr); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedErr, diff)
+			}
+		})
+	}
+
+	t.Run("known hosts file missing", func(t *testing.T) {
+		SetInsecureIgnoreHostKey(false)
+		config := SSHConfig{KnownHosts: "/nonexistent/known_hosts"}
+		if _, err := config.hostKeyCallback(); err == nil {
+			t.Errorf("error expected for missing known_hosts file")
+		}
+	})
+}
+
+func TestClientConfig(t *testing.T) {
+	privateKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	block, _ := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey), []byte("secret"), x509.PEMCipherAES256)
+	keyFile, _ := ioutil.TempFile("", "id_rsa")
+	defer os.Remove(keyFile.Name())
+	_ = pem.Encode(keyFile, block)
+	keyFile.Close()
+
+	type tests = []struct {
+		description  string
+		config       SSHConfig
+		expectedAuth int
+		expectedErr  bool
+	}
+	var clientConfigTests = tests{
+		{"password authentication", SSHConfig{Username: "root", Password: "password"}, 1, false},
+		{"encrypted private key", SSHConfig{Username: "root", PrivateKey: keyFile.Name(), Passphrase: "secret"}, 1, false},
+		{"wrong passphrase", SSHConfig{Username: "root", PrivateKey: keyFile.Name(), Passphrase: "wrong"}, 0, true},
+		{"private key and password", SSHConfig{Username: "root", PrivateKey: keyFile.Name(), Passphrase: "secret", Password: "password"}, 2, false},
+		{"no authentication", SSHConfig{Username: "root"}, 0, true},
+	}
+	SetInsecureIgnoreHostKey(true)
+	defer SetInsecureIgnoreHostKey(false)
+	for _, test := range clientConfigTests {
+		t.Run(test.description, func(t *testing.T) {
+			config, release, err := test.config.clientConfig()
+			defer release()
+			if diff := cmp.Diff(err != nil, test.expectedErr); diff != "" {
+				t.Errorf("%T differ (-got, +want): %s", test.expectedErr, diff)
+			}
+			if err == nil {
+				if diff := cmp.Diff(len(config.Auth), test.expectedAuth); diff != "" {
+					t.Errorf("%T differ (-got, +want): %s", test.expectedAuth, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestDialSSHJumpHosts(t *testing.T) {
+	// nothing listens on the port of the first jump host
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	SetInsecureIgnoreHostKey(true)
+	defer SetInsecureIgnoreHostKey(false)
+	t.Run("first unreachable jump host reported", func(t *testing.T) {
+		config := SSHConfig{Username: "root", Password: "password", JumpHosts: []JumpHost{
+			{Address: "127.0.0.1", SSHConfig: SSHConfig{Username: "jump", Password: "password", Port: port}},
+			{Address: "bastion-2", SSHConfig: SSHConfig{Username: "jump", Password: "password"}},
+		}}
+		_, err := DialSSH(config, "10.0.0.1")
+		if err == nil || !strings.Contains(err.Error(), "connecting to jump host 127.0.0.1 failed") {
+			t.Errorf("jump host error expected, got: %v", err)
+		}
+	})
+	t.Run("authentication of jump host verified", func(t *testing.T) {
+		config := SSHConfig{Username: "root", Password: "password", JumpHosts: []JumpHost{
+			{Address: "bastion-1", SSHConfig: SSHConfig{Username: "jump"}},
+		}}
+		_, err := DialSSH(config, "10.0.0.1")
+		if err == nil || !strings.Contains(err.Error(), "no SSH authentication configured") {
+			t.Errorf("authentication error expected, got: %v", err)
+		}
+	})
+}